@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// asciiSafeWriter rewrites everything written through it as ASCII for --ascii-safe,
+// rendering each non-ASCII character as U+XXXX and each invalid byte as \xNN. Writes
+// are expected to hold complete characters, as the report's formatted lines do.
+type asciiSafeWriter struct {
+	w io.Writer
+}
+
+func (a asciiSafeWriter) Write(p []byte) (int, error) {
+	var out bytes.Buffer
+	out.Grow(len(p))
+	for data := p; len(data) > 0; {
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r < utf8.RuneSelf:
+			out.WriteByte(data[0])
+		case r == utf8.RuneError && size == 1:
+			_, _ = fmt.Fprintf(&out, `\x%02X`, data[0])
+		default:
+			_, _ = fmt.Fprintf(&out, "U+%04X", r)
+		}
+		data = data[size:]
+	}
+	if _, err := a.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// asciiSafeJSON escapes every non-ASCII character in a JSON document as \uXXXX, using
+// a surrogate pair above U+FFFF, so the document is pure ASCII but decodes the same.
+// Non-ASCII can only appear inside strings, where these escapes are valid.
+func asciiSafeJSON(data []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(data))
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r < utf8.RuneSelf:
+			out.WriteByte(data[0])
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			_, _ = fmt.Fprintf(&out, `\u%04x\u%04x`, r1, r2)
+		default:
+			_, _ = fmt.Fprintf(&out, `\u%04x`, r)
+		}
+		data = data[size:]
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func assertASCII(t *testing.T, output string) {
+	t.Helper()
+	for i := 0; i < len(output); i++ {
+		if output[i] > 0x7F {
+			t.Fatalf("Output has non-ASCII byte 0x%02X at %d: %q", output[i], i, output)
+		}
+	}
+}
+
+func TestDestroyEmojisASCIISafeText(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Hi 😊 and ✨"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("ascii-safe", "true")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	assertASCII(t, output)
+	if !bytes.Contains([]byte(output), []byte("U+1F60A")) {
+		t.Errorf("Output should render the emoji as U+1F60A, got %q", output)
+	}
+}
+
+func TestDestroyEmojisASCIISafeJSON(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Hi 😊 and ✨"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("ascii-safe", "true")
+	_ = cmd.Flags().Set("output", "json")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	assertASCII(t, output)
+	var report JSONOutput
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(report.Files) != 1 || len(report.Files[0].EmojisFound) != 2 || report.Files[0].EmojisFound[0] != "😊" {
+		t.Errorf("Escaped JSON should decode to the original emojis, got %+v", report.Files)
+	}
+}
+
+func TestASCIISafeWriter(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := (asciiSafeWriter{&buf}).Write([]byte("a 😊 \xff")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := `a U+1F60A \xFF`; buf.String() != want {
+		t.Errorf("Write() = %q, want %q", buf.String(), want)
+	}
+}
@@ -3,14 +3,25 @@ package commands
 
 import (
 	"bufio"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"emoji-search-and-destroy/pkg/emoji"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding"
 )
 
 // DestroyEmojis is the main command handler that processes a directory to find and remove emojis.
@@ -20,28 +31,232 @@ func DestroyEmojis(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	results, err := processInput(args[0], config)
-	if err != nil {
-		return err
+	// Text passed on the command line is handled before any path logic
+	if config.hasText {
+		return processTextArgument(newProcessor(config), config)
+	}
+	if config.inPlaceFrom != "" {
+		return processInPlace(newProcessor(config), config)
+	}
+	if len(config.dirs) > 0 {
+		if len(args) != 0 {
+			return fmt.Errorf("--dirs-from cannot be used with a directory argument")
+		}
+		if config.gitTrackedOnly {
+			return fmt.Errorf("--git-tracked-only requires a directory argument")
+		}
+		// The directories stand in for the argument; "." only serves as the tar root
+		args = []string{"."}
+	} else if len(args) == 0 {
+		args = []string{defaultTarget()}
+	} else if len(args) != 1 {
+		return fmt.Errorf("requires a directory argument, '-' for stdin, --dirs-from, or --text")
+	}
+
+	targets := args
+	if len(config.dirs) > 0 {
+		targets = config.dirs
+	}
+	for _, target := range targets {
+		if !config.dryRun && !config.confirmed && target != "-" && isRepoRoot(target) {
+			return fmt.Errorf("%s is a git repository root; pass --i-understand or --yes to modify it with --no-dry-run", target)
+		}
 	}
 
 	// Check if we're processing stdin content directly (not file paths)
 	isStdinContent := args[0] == "-" && !config.filesFromStdin
-	return outputResults(results, config, isStdinContent)
+	if config.check && isStdinContent {
+		return fmt.Errorf("--check requires a directory or --files-from-stdin")
+	}
+	if config.gzipOutput && isStdinContent {
+		return fmt.Errorf("--gzip-output cannot be used with stdin content processing (use --files-from-stdin for file lists)")
+	}
+	if (config.output == "html" || config.output == outputFormatJUnit || config.output == outputFormatTar) && isStdinContent {
+		return fmt.Errorf("--output %s cannot be used with stdin content processing (use --files-from-stdin for file lists)", config.output)
+	}
+	if config.checkpoint != "" && args[0] == "-" {
+		return fmt.Errorf("--checkpoint requires a directory argument")
+	}
+	if config.gitTrackedOnly && args[0] == "-" {
+		return fmt.Errorf("--git-tracked-only requires a directory argument")
+	}
+
+	// In JSON mode stdout must carry only the JSON document, so cleaned stdin
+	// content is captured and embedded in the document instead of printed.
+	var cleanedContent strings.Builder
+	contentOut := io.Writer(os.Stdout)
+	if config.output == "json" {
+		contentOut = &cleanedContent
+	}
+
+	processor := newProcessor(config)
+	if config.gitTrackedOnly {
+		tracked, err := emoji.GitTrackedFiles(args[0])
+		if err != nil {
+			return err
+		}
+		processor.OnlyFiles = tracked
+	}
+	if config.checkpoint != "" {
+		checkpoint, err := emoji.OpenCheckpoint(config.checkpoint)
+		if err != nil {
+			return err
+		}
+		processor.Checkpoint = checkpoint
+	}
+
+	stopProgress := func() {}
+	if args[0] != "-" {
+		stopProgress = startProgress(processor, config)
+	}
+	var scanned *scannedFiles
+	if config.output == outputFormatJUnit {
+		scanned = trackScanned(processor)
+	}
+	config.logger.Info("run started", "target", args[0], "dry_run", config.dryRun)
+	start := time.Now()
+	results, err := processInput(processor, args[0], config, contentOut)
+	stopProgress()
+	if checkpointErr := finishCheckpoint(processor.Checkpoint, err); err == nil {
+		err = checkpointErr
+	}
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Emptied {
+			config.logger.Warn("cleaned content is empty", "path", result.FilePath, "write_refused", result.WriteRefused)
+		}
+	}
+	config.logger.Info("run finished", "target", args[0], "files_with_emojis", len(results), "duration", time.Since(start))
+
+	if !config.quiet {
+		for _, path := range processor.Deduped {
+			config.logger.Warn("skipped hardlink to already processed file", "path", path)
+		}
+		for _, skipped := range processor.Skipped {
+			config.logger.Warn("skipped file", "path", skipped.Path, "reason", skipped.Reason)
+		}
+		for _, warning := range processor.Warnings {
+			config.logger.Warn("skipped unreadable file", "error", warning)
+		}
+	}
+
+	if config.output == outputFormatTar && !config.check {
+		root := args[0]
+		if root == "-" {
+			root = "."
+		}
+		return outputTar(os.Stdout, processor, reportedResults(results, config), root)
+	}
+
+	// Paths are made relative only for reporting, once nothing needs to read the files
+	relativeRoot := ""
+	if config.relative && args[0] != "-" && len(config.dirs) == 0 {
+		relativeRoot = args[0]
+		results = relativeResults(results, relativeRoot)
+	}
+
+	if config.check {
+		return checkResults(cmd, results, config)
+	}
+
+	if scanned != nil {
+		list := scanned.list()
+		if relativeRoot != "" {
+			for i, path := range list {
+				list[i] = relativePath(relativeRoot, path)
+			}
+		}
+		return outputJUnit(os.Stdout, reportedResults(results, config), list)
+	}
+
+	return outputResults(results, config, isStdinContent, cleanedContent.String())
 }
 
 // commandConfig holds the parsed command flags
 type commandConfig struct {
-	dryRun         bool
-	listOnly       bool
-	exclude        []string
-	output         string
-	filesFromStdin bool
-	quiet          bool
-	allowFile      string
-	allowedEmojis  []string
+	dryRun          bool
+	listOnly        bool
+	exclude         []string
+	excludeRegexps  []*regexp.Regexp
+	output          string
+	filesFromStdin  bool
+	dirs            []string // Directories listed by --dirs-from
+	quiet           bool
+	allowFile       string
+	allowedEmojis   []string
+	allowByExt      map[string][]string
+	scanGzip        bool
+	scanZip         bool
+	assertNoWrites  bool
+	trace           bool
+	squeezeBlank    bool
+	dedupeHardlinks bool
+	includeModTime  bool
+	limit           int
+	workers         int
+	maxOpenFiles    int
+	sinceMtime      time.Duration
+	whitespace      emoji.WhitespacePolicy
+	htmlEntities    bool
+	escapes         bool
+	manifest        bool
+	emojiOnlyLines  bool
+	deleteEmojiOnly bool
+	gzipOutput      bool
+	hashCleaned     bool
+	noSkipBinary    bool
+	skipByMIME      bool
+	columnMode      emoji.ColumnMode
+	confirmed       bool
+	sortEmojis      bool
+	reportSort      string
+	inPlaceFrom     string
+	jsonIndent      string
+	minEmojis       int
+	minDensity      float64
+	logger          *slog.Logger
+	protect         []string
+	checkpoint      string
+	bidiCleanup     bool
+	groupClusters   bool
+	bmpOnly         bool
+	never           []rune
+	gitTrackedOnly  bool
+	warnOnEmpty     bool
+	refuseEmpty     bool
+	print0          bool
+	relative        bool
+	structured      emoji.StructuredFormat
+	markdownAware   bool
+	asciiSafe       bool
+	asciiFallback   bool
+	groupBy         string
+	text            string
+	hasText         bool
+	replacements    map[string]string
+	check           bool
+	reportOnly      bool // Exit 0 from --check even when files would change
+	staged          bool
+	preview         bool
+	minFileSize     int64
+	maxFileSize     int64
+	maxLines        int
+	skipSymlinks    bool
+	inputCharset    encoding.Encoding
 }
 
+// --report-sort values ordering the files in a report
+const (
+	reportSortPath    = "path"
+	reportSortCount   = "count"   // Most emoji occurrences first
+	reportSortSavings = "savings" // Most bytes removed first
+)
+
+// groupByDir is the --group-by value that groups report entries by parent directory
+const groupByDir = "dir"
+
 // parseFlags extracts and validates command flags
 func parseFlags(cmd *cobra.Command) (*commandConfig, error) {
 	noDryRun, err := cmd.Flags().GetBool("no-dry-run")
@@ -59,6 +274,25 @@ func parseFlags(cmd *cobra.Command) (*commandConfig, error) {
 		return nil, fmt.Errorf("failed to get exclude flag: %w", err)
 	}
 
+	excludeRegex, err := cmd.Flags().GetStringSlice("exclude-regex")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exclude-regex flag: %w", err)
+	}
+
+	protect, err := cmd.Flags().GetStringSlice("protect")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protect flag: %w", err)
+	}
+
+	excludeRegexps := make([]*regexp.Regexp, 0, len(excludeRegex))
+	for _, pattern := range excludeRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude-regex %q: %w", pattern, err)
+		}
+		excludeRegexps = append(excludeRegexps, re)
+	}
+
 	output, err := cmd.Flags().GetString("output")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get output flag: %w", err)
@@ -79,49 +313,728 @@ func parseFlags(cmd *cobra.Command) (*commandConfig, error) {
 		return nil, fmt.Errorf("failed to get allow-file flag: %w", err)
 	}
 
+	scanGzip, err := cmd.Flags().GetBool("scan-gz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scan-gz flag: %w", err)
+	}
+
+	scanZip, err := cmd.Flags().GetBool("scan-zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scan-zip flag: %w", err)
+	}
+
+	assertNoWrites, err := cmd.Flags().GetBool("assert-no-writes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assert-no-writes flag: %w", err)
+	}
+
+	trace, err := cmd.Flags().GetBool("trace")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trace flag: %w", err)
+	}
+
+	squeezeBlank, err := cmd.Flags().GetBool("squeeze-blank-lines")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get squeeze-blank-lines flag: %w", err)
+	}
+
+	dedupeHardlinks, err := cmd.Flags().GetBool("dedupe-across-run")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dedupe-across-run flag: %w", err)
+	}
+
+	includeModTime, err := cmd.Flags().GetBool("include-mtime")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get include-mtime flag: %w", err)
+	}
+
+	sinceMtime, err := cmd.Flags().GetDuration("since-mtime")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get since-mtime flag: %w", err)
+	}
+	if sinceMtime < 0 {
+		return nil, fmt.Errorf("invalid since-mtime: %s (must be positive)", sinceMtime)
+	}
+
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limit flag: %w", err)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit: %d (must be zero or positive)", limit)
+	}
+
+	workers, err := cmd.Flags().GetInt("workers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workers flag: %w", err)
+	}
+	if workers < 0 {
+		return nil, fmt.Errorf("invalid workers: %d (must be zero or positive)", workers)
+	}
+
+	maxOpenFiles, err := cmd.Flags().GetInt("max-open-files")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max-open-files flag: %w", err)
+	}
+	if maxOpenFiles < 0 {
+		return nil, fmt.Errorf("invalid max-open-files: %d (must be zero or positive)", maxOpenFiles)
+	}
+	if maxOpenFiles > 0 && workers == 0 {
+		return nil, fmt.Errorf("--max-open-files requires --workers")
+	}
+
+	charsetName, err := cmd.Flags().GetString("input-charset")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input-charset flag: %w", err)
+	}
+	var inputCharset encoding.Encoding
+	if charsetName != "" {
+		inputCharset, err = emoji.LookupCharset(charsetName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxLines, err := cmd.Flags().GetInt("max-lines")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max-lines flag: %w", err)
+	}
+	if maxLines < 0 {
+		return nil, fmt.Errorf("invalid max-lines: %d (must be zero or positive)", maxLines)
+	}
+
+	skipSymlinks, err := cmd.Flags().GetBool("skip-symlinks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skip-symlinks flag: %w", err)
+	}
+
+	whitespaceFlag, err := cmd.Flags().GetString("whitespace")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get whitespace flag: %w", err)
+	}
+	whitespace, err := emoji.ParseWhitespacePolicy(whitespaceFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	collapseBetween, err := cmd.Flags().GetBool("collapse-between")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collapse-between flag: %w", err)
+	}
+	if collapseBetween {
+		if whitespace != emoji.WhitespaceKeep && whitespace != emoji.WhitespaceCollapseBetween {
+			return nil, fmt.Errorf("--collapse-between cannot be used with --whitespace %s", whitespace)
+		}
+		whitespace = emoji.WhitespaceCollapseBetween
+	}
+
+	htmlEntities, err := cmd.Flags().GetBool("decode-html-entities")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decode-html-entities flag: %w", err)
+	}
+
+	asciiFallback, err := cmd.Flags().GetBool("ascii")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ascii flag: %w", err)
+	}
+
+	groupBy, err := cmd.Flags().GetString("group-by")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group-by flag: %w", err)
+	}
+	if groupBy != "" && groupBy != groupByDir {
+		return nil, fmt.Errorf("invalid group-by: %s (must be '%s')", groupBy, groupByDir)
+	}
+
+	dirsFrom, err := cmd.Flags().GetString("dirs-from")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dirs-from flag: %w", err)
+	}
+	var dirs []string
+	if dirsFrom != "" {
+		dirs, err = loadDirsFile(dirsFrom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dirs file: %w", err)
+		}
+	}
+
+	text, err := cmd.Flags().GetString("text")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get text flag: %w", err)
+	}
+
+	replaceMapFile, err := cmd.Flags().GetString("replace-map")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replace-map flag: %w", err)
+	}
+
+	var replacements map[string]string
+	if replaceMapFile != "" {
+		replacements, err = loadReplaceMap(replaceMapFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load replace map: %w", err)
+		}
+	}
+
+	check, err := cmd.Flags().GetBool("check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check flag: %w", err)
+	}
+	if check && (noDryRun || cmd.Flags().Changed("text")) {
+		return nil, fmt.Errorf("--check cannot be used with --no-dry-run or --text")
+	}
+
+	reportOnly, err := cmd.Flags().GetBool("report-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report-only flag: %w", err)
+	}
+
+	staged, err := cmd.Flags().GetBool("staged")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged flag: %w", err)
+	}
+
+	minFileSize, err := parseSizeFlag(cmd, "min-file-size")
+	if err != nil {
+		return nil, err
+	}
+
+	maxFileSize, err := parseSizeFlag(cmd, "max-file-size")
+	if err != nil {
+		return nil, err
+	}
+	if minFileSize > 0 && maxFileSize > 0 && minFileSize > maxFileSize {
+		return nil, fmt.Errorf("--min-file-size (%d bytes) is larger than --max-file-size (%d bytes)", minFileSize, maxFileSize)
+	}
+
+	preview, err := cmd.Flags().GetBool("preview")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preview flag: %w", err)
+	}
+
+	escapes, err := cmd.Flags().GetBool("decode-escapes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decode-escapes flag: %w", err)
+	}
+
+	manifest, err := cmd.Flags().GetBool("manifest")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest flag: %w", err)
+	}
+
+	emojiOnlyLines, err := cmd.Flags().GetBool("emoji-only-lines")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emoji-only-lines flag: %w", err)
+	}
+
+	deleteEmojiOnly, err := cmd.Flags().GetBool("delete-emoji-only-lines")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delete-emoji-only-lines flag: %w", err)
+	}
+
+	gzipOutput, err := cmd.Flags().GetBool("gzip-output")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gzip-output flag: %w", err)
+	}
+	if gzipOutput && output != "json" {
+		return nil, fmt.Errorf("--gzip-output requires --output json")
+	}
+
+	hashCleaned, err := cmd.Flags().GetBool("hash-cleaned")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hash-cleaned flag: %w", err)
+	}
+
+	noSkipBinary, err := cmd.Flags().GetBool("no-skip-binary")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-skip-binary flag: %w", err)
+	}
+
+	skipByMIME, err := cmd.Flags().GetBool("exclude-binary-by-mime")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exclude-binary-by-mime flag: %w", err)
+	}
+
+	columnModeStr, err := cmd.Flags().GetString("column-mode")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column-mode flag: %w", err)
+	}
+
+	columnMode, err := emoji.ParseColumnMode(columnModeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	iUnderstand, err := cmd.Flags().GetBool("i-understand")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get i-understand flag: %w", err)
+	}
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get yes flag: %w", err)
+	}
+
+	sortEmojis, err := cmd.Flags().GetBool("sort-emojis")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sort-emojis flag: %w", err)
+	}
+
+	reportSort, err := cmd.Flags().GetString("report-sort")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report-sort flag: %w", err)
+	}
+	switch reportSort {
+	case "", reportSortPath, reportSortCount, reportSortSavings:
+	default:
+		return nil, fmt.Errorf("invalid report-sort: %s (must be '%s', '%s' or '%s')", reportSort, reportSortPath, reportSortCount, reportSortSavings)
+	}
+
+	inPlaceFrom, err := cmd.Flags().GetString("in-place-from")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get in-place-from flag: %w", err)
+	}
+	if inPlaceFrom != "" && cmd.Flags().Changed("text") {
+		return nil, fmt.Errorf("--in-place-from cannot be used with --text")
+	}
+	if output != "text" && output != "json" && cmd.Flags().Changed("text") {
+		return nil, fmt.Errorf("--output %s cannot be used with --text", output)
+	}
+
+	jsonIndentStr, err := cmd.Flags().GetString("json-indent")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get json-indent flag: %w", err)
+	}
+
+	jsonIndent, err := parseJSONIndent(jsonIndentStr)
+	if err != nil {
+		return nil, err
+	}
+
+	minEmojis, err := cmd.Flags().GetInt("min-emojis")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get min-emojis flag: %w", err)
+	}
+	if minEmojis < 0 {
+		return nil, fmt.Errorf("invalid min-emojis: %d (must be zero or positive)", minEmojis)
+	}
+
+	minDensity, err := cmd.Flags().GetFloat64("min-density")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get min-density flag: %w", err)
+	}
+	if minDensity < 0 || minDensity >= 100 {
+		return nil, fmt.Errorf("invalid min-density: %g (must be at least 0 and below 100)", minDensity)
+	}
+
+	logFormat, err := cmd.Flags().GetString("log-format")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log-format flag: %w", err)
+	}
+
+	logLevel, err := cmd.Flags().GetString("log-level")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log-level flag: %w", err)
+	}
+
+	logger, err := newLogger(os.Stderr, logFormat, logLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint flag: %w", err)
+	}
+	if checkpoint != "" && staged {
+		return nil, fmt.Errorf("--checkpoint cannot be used with --staged")
+	}
+	if workers > 0 && staged {
+		return nil, fmt.Errorf("--workers cannot be used with --staged")
+	}
+
+	bidiCleanup, err := cmd.Flags().GetBool("bidi-cleanup")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bidi-cleanup flag: %w", err)
+	}
+
+	groupClusters, err := cmd.Flags().GetBool("group-clusters")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group-clusters flag: %w", err)
+	}
+
+	bmpOnly, err := cmd.Flags().GetBool("bmp-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bmp-only flag: %w", err)
+	}
+
+	neverValues, err := cmd.Flags().GetStringSlice("never")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get never flag: %w", err)
+	}
+	var never []rune
+	for _, value := range neverValues {
+		r, err := parseNeverValue(value)
+		if err != nil {
+			return nil, err
+		}
+		never = append(never, r)
+	}
+
+	gitTrackedOnly, err := cmd.Flags().GetBool("git-tracked-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git-tracked-only flag: %w", err)
+	}
+
+	warnOnEmpty, err := cmd.Flags().GetBool("warn-on-empty")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warn-on-empty flag: %w", err)
+	}
+
+	refuseEmpty, err := cmd.Flags().GetBool("refuse-empty")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refuse-empty flag: %w", err)
+	}
+
+	print0, err := cmd.Flags().GetBool("print0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get print0 flag: %w", err)
+	}
+
+	relative, err := cmd.Flags().GetBool("relative")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relative flag: %w", err)
+	}
+
+	structuredStr, err := cmd.Flags().GetString("structured")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get structured flag: %w", err)
+	}
+
+	asciiSafe, err := cmd.Flags().GetBool("ascii-safe")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ascii-safe flag: %w", err)
+	}
+
+	markdownAware, err := cmd.Flags().GetBool("markdown-aware")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get markdown-aware flag: %w", err)
+	}
+
+	var structured emoji.StructuredFormat
+	if structuredStr != "" {
+		if structured, err = emoji.ParseStructuredFormat(structuredStr); err != nil {
+			return nil, err
+		}
+	}
+	if print0 && !listOnly && !check {
+		return nil, fmt.Errorf("--print0 requires --list-only or --check")
+	}
+
 	// Validate output format
-	if output != "text" && output != "json" {
-		return nil, fmt.Errorf("invalid output format: %s (must be 'text' or 'json')", output)
+	if output != "text" && output != "json" && output != "html" && output != outputFormatJUnit && output != outputFormatTar && output != outputFormatShort {
+		return nil, fmt.Errorf("invalid output format: %s (must be 'text', 'json', 'html', 'junit', 'tar' or 'short')", output)
+	}
+	if output == outputFormatTar && noDryRun {
+		return nil, fmt.Errorf("--output tar cannot be used with --no-dry-run")
+	}
+
+	requireAllowFile, err := cmd.Flags().GetBool("require-allow-file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get require-allow-file flag: %w", err)
+	}
+
+	allowValues, err := cmd.Flags().GetStringSlice("allow")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allow flag: %w", err)
 	}
 
 	// Load allowed emojis
-	var allowedEmojis []string
+	var allowConfig emoji.AllowConfig
 	if allowFile != "" {
-		allowedEmojis, err = loadAllowFile(allowFile)
+		allowConfig, err = loadAllowFile(allowFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load allow file: %w", err)
 		}
 	} else {
 		// Check for default .emoji-sad-allow file
-		if _, err := os.Stat(".emoji-sad-allow"); err == nil {
-			allowedEmojis, err = loadAllowFile(".emoji-sad-allow")
+		if _, err := os.Stat(defaultAllowFile); err == nil {
+			allowConfig, err = loadAllowFile(defaultAllowFile)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load default allow file: %w", err)
 			}
+		} else if requireAllowFile {
+			return nil, fmt.Errorf("allow file is required but %s was not found", defaultAllowFile)
+		}
+	}
+	allowedEmojis := allowConfig.Allowed
+
+	// Inline --allow values are merged with the allow file, including its extension sections
+	for _, value := range allowValues {
+		allowed, err := parseAllowValue(value)
+		if err != nil {
+			return nil, err
+		}
+		allowedEmojis = append(allowedEmojis, allowed)
+		for ext, list := range allowConfig.ByExtension {
+			allowConfig.ByExtension[ext] = append(list, allowed)
 		}
 	}
 
 	return &commandConfig{
-		dryRun:         !noDryRun,
-		listOnly:       listOnly,
-		exclude:        exclude,
-		output:         output,
-		filesFromStdin: filesFromStdin,
-		quiet:          quiet,
-		allowFile:      allowFile,
-		allowedEmojis:  allowedEmojis,
+		dryRun:          !noDryRun,
+		listOnly:        listOnly,
+		exclude:         exclude,
+		excludeRegexps:  excludeRegexps,
+		output:          output,
+		filesFromStdin:  filesFromStdin || manifest, // A manifest is a file list with directives
+		dirs:            dirs,
+		manifest:        manifest,
+		emojiOnlyLines:  emojiOnlyLines,
+		deleteEmojiOnly: deleteEmojiOnly,
+		gzipOutput:      gzipOutput,
+		hashCleaned:     hashCleaned,
+		noSkipBinary:    noSkipBinary,
+		skipByMIME:      skipByMIME,
+		columnMode:      columnMode,
+		confirmed:       iUnderstand || yes,
+		sortEmojis:      sortEmojis,
+		reportSort:      reportSort,
+		inPlaceFrom:     inPlaceFrom,
+		jsonIndent:      jsonIndent,
+		minEmojis:       minEmojis,
+		minDensity:      minDensity,
+		logger:          logger,
+		protect:         protect,
+		checkpoint:      checkpoint,
+		bidiCleanup:     bidiCleanup,
+		groupClusters:   groupClusters,
+		bmpOnly:         bmpOnly,
+		never:           never,
+		gitTrackedOnly:  gitTrackedOnly,
+		warnOnEmpty:     warnOnEmpty,
+		refuseEmpty:     refuseEmpty,
+		print0:          print0,
+		relative:        relative,
+		structured:      structured,
+		markdownAware:   markdownAware,
+		asciiSafe:       asciiSafe,
+		quiet:           quiet,
+		allowFile:       allowFile,
+		allowedEmojis:   allowedEmojis,
+		allowByExt:      allowConfig.ByExtension,
+		scanGzip:        scanGzip,
+		scanZip:         scanZip,
+		assertNoWrites:  assertNoWrites,
+		trace:           trace,
+		squeezeBlank:    squeezeBlank,
+		dedupeHardlinks: dedupeHardlinks,
+		includeModTime:  includeModTime,
+		limit:           limit,
+		workers:         workers,
+		maxOpenFiles:    maxOpenFiles,
+		sinceMtime:      sinceMtime,
+		whitespace:      whitespace,
+		htmlEntities:    htmlEntities,
+		escapes:         escapes,
+		asciiFallback:   asciiFallback,
+		groupBy:         groupBy,
+		text:            text,
+		hasText:         cmd.Flags().Changed("text"),
+		replacements:    replacements,
+		check:           check,
+		reportOnly:      reportOnly,
+		staged:          staged,
+		preview:         preview,
+		minFileSize:     minFileSize,
+		maxFileSize:     maxFileSize,
+		maxLines:        maxLines,
+		skipSymlinks:    skipSymlinks,
+		inputCharset:    inputCharset,
 	}, nil
 }
 
-// loadAllowFile loads allowed emojis from a file, one per line
-func loadAllowFile(filepath string) ([]string, error) {
+// targetEnvVar names the environment variable holding the target used when no
+// argument is given, for containers that inject the path.
+const targetEnvVar = "EMOJI_SAD_TARGET"
+
+// defaultTarget returns the target used when no argument is given: the value of
+// EMOJI_SAD_TARGET if it is set, else the current directory.
+func defaultTarget() string {
+	if target := os.Getenv(targetEnvVar); target != "" {
+		return target
+	}
+	return "."
+}
+
+// isRepoRoot reports whether dir is the root of a git repository, that is,
+// whether it directly contains a .git directory or (for worktrees) a .git file.
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// defaultAllowFile is the allow file loaded from the current directory when --allow-file is not given
+const defaultAllowFile = ".emoji-sad-allow"
+
+// loadAllowFile loads allowed emojis from a file, one per line, with optional
+// per-extension sections
+func loadAllowFile(filepath string) (emoji.AllowConfig, error) {
 	// Validate filepath to prevent directory traversal
 	if filepath == "" {
-		return nil, fmt.Errorf("filepath cannot be empty")
+		return emoji.AllowConfig{}, fmt.Errorf("filepath cannot be empty")
 	}
 
 	// #nosec G304 - This is an intentional file read for allow file functionality
 	file, err := os.Open(filepath)
+	if err != nil {
+		return emoji.AllowConfig{}, err
+	}
+	defer func() {
+		_ = file.Close() // Ignore close error in defer
+	}()
+
+	return emoji.ParseAllowConfig(file)
+}
+
+// loadDirsFile reads the directories to scan from a file, one per line. Blank lines
+// and lines starting with # are skipped.
+func loadDirsFile(path string) ([]string, error) {
+	// #nosec G304 - This is an intentional file read for dirs file functionality
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, line := range strings.Split(string(content), "\n") {
+		dir := strings.TrimSpace(line)
+		if dir == "" || strings.HasPrefix(dir, "#") {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no directories listed in %s", path)
+	}
+	return dirs, nil
+}
+
+// parseAllowValue converts an --allow value, a literal emoji or a code point
+// written as U+XXXX, into the emoji it allows
+func parseAllowValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("invalid allow value: must not be empty")
+	}
+	if !strings.HasPrefix(strings.ToUpper(value), "U+") {
+		return value, nil
+	}
+
+	code, err := strconv.ParseInt(value[2:], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return "", fmt.Errorf("invalid allow value: %s is not a valid code point", value)
+	}
+	return string(rune(code)), nil
+}
+
+// parseNeverValue converts a --never value, a literal character or a code point
+// written as U+XXXX, into the code point excluded from detection
+func parseNeverValue(value string) (rune, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(strings.ToUpper(value), "U+") {
+		code, err := strconv.ParseInt(value[2:], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return 0, fmt.Errorf("invalid never value: %s is not a valid code point", value)
+		}
+		return rune(code), nil
+	}
+
+	// A trailing variation selector, as in a pasted gear emoji, only picks the presentation
+	char := strings.TrimSuffix(value, "\uFE0F")
+	if utf8.RuneCountInString(char) != 1 {
+		return 0, fmt.Errorf("invalid never value: %q must be a single character or a U+XXXX code point", value)
+	}
+	r, _ := utf8.DecodeRuneInString(char)
+	return r, nil
+}
+
+// sizeUnits maps size suffixes to their multipliers in bytes
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+}
+
+// parseSize parses a human-readable size such as "512", "10KB" or "1.5M" into bytes.
+// Units are powers of 1024 and case-insensitive.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if split < 0 {
+		split = len(s)
+	}
+
+	number, unit := s[:split], strings.ToLower(strings.TrimSpace(s[split:]))
+	multiplier, ok := sizeUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid size: %q (expected a number with an optional B, KB, MB or GB suffix)", s)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// parseSizeFlag reads a size flag, treating an empty value as no limit
+func parseSizeFlag(cmd *cobra.Command, name string) (int64, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s flag: %w", name, err)
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	size, err := parseSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --%s: %w", name, err)
+	}
+	return size, nil
+}
+
+// maxJSONIndent bounds the number of spaces accepted by --json-indent
+const maxJSONIndent = 16
+
+// parseJSONIndent converts a --json-indent value, a number of spaces or "tab",
+// into the indent string passed to json.MarshalIndent
+func parseJSONIndent(value string) (string, error) {
+	if value == "tab" {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > maxJSONIndent {
+		return "", fmt.Errorf("invalid json-indent: %s (must be 'tab' or a number of spaces from 0 to %d)", value, maxJSONIndent)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+// loadReplaceMap loads per-emoji substitutions from a file of emoji=replacement lines
+func loadReplaceMap(filepath string) (map[string]string, error) {
+	// #nosec G304 - This is an intentional file read for replace map functionality
+	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -129,79 +1042,355 @@ func loadAllowFile(filepath string) ([]string, error) {
 		_ = file.Close() // Ignore close error in defer
 	}()
 
-	var allowed []string
+	replacements := make(map[string]string)
 	scanner := bufio.NewScanner(file)
+	lineNum := 0
 	for scanner.Scan() {
-		emoji := strings.TrimSpace(scanner.Text())
-		if emoji != "" && !strings.HasPrefix(emoji, "#") { // Skip empty lines and comments
-			allowed = append(allowed, emoji)
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") { // Skip empty lines and comments
+			continue
+		}
+
+		emoji, replacement, found := strings.Cut(line, "=")
+		emoji = strings.TrimSpace(emoji)
+		if !found || emoji == "" {
+			return nil, fmt.Errorf("invalid line %d: expected emoji=replacement", lineNum)
 		}
+		replacements[emoji] = replacement
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
 
-	return allowed, nil
+	return replacements, nil
 }
 
-// processInput processes either stdin or directory input
-func processInput(dirPath string, config *commandConfig) ([]emoji.ProcessResult, error) {
+// newProcessor creates a file processor configured from the command flags
+func newProcessor(config *commandConfig) *emoji.FileProcessor {
 	processor := emoji.NewFileProcessorWithExcludesAndAllowed(config.exclude, config.allowedEmojis)
+	processor.AllowByExtension = config.allowByExt
+	processor.NoSkipBinary = config.noSkipBinary
+	processor.SkipBinaryByMIME = config.skipByMIME
+	processor.ScanGzip = config.scanGzip
+	processor.ScanZip = config.scanZip
+	processor.ExcludeRegexps = config.excludeRegexps
+	processor.Protect = config.protect
+	processor.WarnOnEmpty = config.warnOnEmpty
+	processor.RefuseEmpty = config.refuseEmpty
+	processor.AssertNoWrites = config.assertNoWrites
+	processor.SqueezeBlankLines = config.squeezeBlank
+	processor.ReportEmojiOnlyLines = config.emojiOnlyLines
+	processor.DeleteEmojiOnlyLines = config.deleteEmojiOnly
+	processor.ASCIIFallback = config.asciiFallback
+	processor.DedupeHardlinks = config.dedupeHardlinks
+	processor.IncludeModTime = config.includeModTime
+	processor.RecordFirstEmoji = config.preview
+	processor.ColumnMode = config.columnMode
+	processor.Structured = config.structured
+	processor.MarkdownAware = config.markdownAware
+	// The JSON stats block and --report-sort count both count repeated emojis
+	processor.CountOccurrences = config.minEmojis > 0 || config.output == "json" || config.reportSort == reportSortCount
+	processor.RecordDensity = config.minDensity > 0
+	processor.HashCleaned = config.hashCleaned
+	processor.Limit = config.limit
+	processor.Workers = config.workers
+	processor.MaxOpenFiles = config.maxOpenFiles
+	processor.MinFileSize = config.minFileSize
+	processor.MaxFileSize = config.maxFileSize
+	processor.MaxLines = config.maxLines
+	processor.SkipSymlinks = config.skipSymlinks
+	processor.InputCharset = config.inputCharset
+	if config.sinceMtime > 0 {
+		processor.ModifiedSince = time.Now().Add(-config.sinceMtime)
+	}
+	processor.Detector.WithWhitespacePolicy(config.whitespace).WithHTMLEntities(config.htmlEntities).WithEscapes(config.escapes).WithBidiCleanup(config.bidiCleanup).WithGraphemeClusters(config.groupClusters).WithBMPOnly(config.bmpOnly).WithNever(config.never)
+	if config.replacements != nil {
+		processor.Detector.WithReplacements(config.replacements)
+	}
+	if config.trace {
+		processor.Detector.WithTrace(os.Stderr)
+	}
+	return processor
+}
+
+// finishCheckpoint removes the checkpoint after a successful run, or keeps it for a
+// resume if the run failed
+func finishCheckpoint(checkpoint *emoji.Checkpoint, runErr error) error {
+	if checkpoint == nil {
+		return nil
+	}
+	if runErr != nil {
+		return checkpoint.Close()
+	}
+	return checkpoint.Remove()
+}
 
+// processInput processes either stdin or directory input. Cleaned stdin content is written to contentOut.
+func processInput(processor *emoji.FileProcessor, dirPath string, config *commandConfig, contentOut io.Writer) ([]emoji.ProcessResult, error) {
+	if len(config.dirs) > 0 {
+		return processDirectories(processor, config)
+	}
 	if dirPath == "-" {
+		if config.staged {
+			return nil, fmt.Errorf("--staged requires a directory argument")
+		}
 		if config.listOnly && !config.filesFromStdin {
 			return nil, fmt.Errorf("--list-only cannot be used with stdin content processing (use --files-from-stdin for file lists)")
 		}
 		if config.filesFromStdin {
-			return processFilePathsFromStdin(processor, config.dryRun)
+			return processFilePathsFromStdin(processor, config.dryRun, config.manifest, config.logger)
 		}
-		return processContentFromStdin(processor, config.dryRun)
+		return processContentFromStdin(processor, config.dryRun, contentOut)
 	}
 
 	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
 	}
 
+	return processDirectory(processor, dirPath, config)
+}
+
+// processDirectory processes a single directory, staging the changes with --staged.
+// With --workers the files are processed concurrently.
+func processDirectory(processor *emoji.FileProcessor, dirPath string, config *commandConfig) ([]emoji.ProcessResult, error) {
+	if config.staged && !config.dryRun {
+		return processor.ProcessDirectoryStaged(dirPath)
+	}
+	if config.workers > 0 {
+		return processor.ProcessDirectoryContext(context.Background(), dirPath, config.dryRun)
+	}
 	return processor.ProcessDirectory(dirPath, config.dryRun)
 }
 
+// processDirectories processes each --dirs-from directory in turn, warning about and
+// skipping those that do not exist. --limit covers all the directories together, so
+// each one is processed with what is left of it.
+func processDirectories(processor *emoji.FileProcessor, config *commandConfig) ([]emoji.ProcessResult, error) {
+	limit := processor.Limit
+	defer func() { processor.Limit = limit }()
+
+	var results []emoji.ProcessResult
+	for _, dir := range config.dirs {
+		if limit > 0 {
+			processor.Limit = limit - len(results)
+		}
+
+		info, err := os.Stat(dir)
+		if err != nil {
+			config.logger.Warn("skipping directory", "path", dir, "error", err)
+			continue
+		}
+		if !info.IsDir() {
+			config.logger.Warn("skipping directory", "path", dir, "error", "not a directory")
+			continue
+		}
+
+		dirResults, err := processDirectory(processor, dir, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process %s: %w", dir, err)
+		}
+		results = append(results, dirResults...)
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
 // JSONOutput represents the JSON output structure
 type JSONOutput struct {
-	Summary JSONSummary    `json:"summary"`
-	Files   []JSONFileInfo `json:"files"`
+	Summary        JSONSummary            `json:"summary"`
+	Files          []JSONFileInfo         `json:"files"`
+	ByDirectory    []JSONDirectorySummary `json:"by_directory,omitempty"`
+	CleanedContent string                 `json:"cleaned_content,omitempty"` // Cleaned stdin content, in place of raw stdout
 }
 
 // JSONSummary represents summary information in JSON output
 type JSONSummary struct {
+	TotalFiles  int        `json:"total_files"`
+	TotalEmojis int        `json:"total_emojis"`
+	TotalBytes  int64      `json:"bytes_removed"` // Bytes by which cleaning shrinks the files, or would in a dry run
+	DryRun      bool       `json:"dry_run"`
+	Mode        string     `json:"mode"` // "list", "process"
+	Stats       *JSONStats `json:"stats,omitempty"`
+
+	// MinEmojis, MinDensity and ShownFiles are set with --min-emojis and
+	// --min-density, which limit the files listed but not the totals above
+	MinEmojis  int     `json:"min_emojis,omitempty"`
+	MinDensity float64 `json:"min_density,omitempty"`
+	ShownFiles *int    `json:"shown_files,omitempty"`
+}
+
+// JSONStats represents the distribution of emojis across modified files in JSON output.
+// Emojis are counted per file as occurrences, so each repeat counts.
+type JSONStats struct {
+	Files   int     `json:"files"`
+	Min     int     `json:"min"`
+	Max     int     `json:"max"`
+	Average float64 `json:"average"`
+}
+
+// computeStats returns the emoji distribution across modified files, or nil if no
+// file was modified
+func computeStats(results []emoji.ProcessResult) *JSONStats {
+	var stats *JSONStats
+	total := 0
+	for _, result := range results {
+		if !result.Modified {
+			continue
+		}
+		count := result.Occurrences
+		if stats == nil {
+			stats = &JSONStats{Min: count, Max: count}
+		}
+		stats.Files++
+		stats.Min = min(stats.Min, count)
+		stats.Max = max(stats.Max, count)
+		total += count
+	}
+
+	if stats != nil {
+		stats.Average = float64(total) / float64(stats.Files)
+	}
+	return stats
+}
+
+// JSONDirectorySummary represents per-directory totals in JSON output (for --group-by dir)
+type JSONDirectorySummary struct {
+	Directory   string `json:"directory"`
 	TotalFiles  int    `json:"total_files"`
 	TotalEmojis int    `json:"total_emojis"`
-	DryRun      bool   `json:"dry_run"`
-	Mode        string `json:"mode"` // "list", "process"
+	TotalBytes  int64  `json:"bytes_removed"`
 }
 
 // JSONFileInfo represents file information in JSON output
 type JSONFileInfo struct {
-	FilePath     string   `json:"file_path"`
-	EmojisFound  []string `json:"emojis_found"`
-	OriginalSize int64    `json:"original_size"`
-	NewSize      int64    `json:"new_size,omitempty"`
-	Modified     bool     `json:"modified"`
+	FilePath           string            `json:"file_path"`
+	EmojisFound        []string          `json:"emojis_found"`
+	OriginalSize       int64             `json:"original_size"`
+	NewSize            int64             `json:"new_size,omitempty"`
+	Modified           bool              `json:"modified"`
+	ModifiedTime       *time.Time        `json:"modified_time,omitempty"`
+	EmojiOnlyLines     []int             `json:"emoji_only_lines,omitempty"`
+	CleanedSHA256      string            `json:"cleaned_sha256,omitempty"`
+	Occurrences        int               `json:"occurrences,omitempty"`
+	Protected          bool              `json:"protected,omitempty"`
+	Emptied            bool              `json:"emptied,omitempty"`
+	WriteRefused       bool              `json:"write_refused,omitempty"`
+	LineEndingConflict bool              `json:"line_ending_conflict,omitempty"`
+	Preserved          []string          `json:"preserved_emojis,omitempty"`
+	Replacements       map[string]string `json:"replacements,omitempty"`
+	Density            float64           `json:"density,omitempty"`
+}
+
+// relativeResults returns a copy of results with each path made relative to root,
+// the directory that was scanned (for --relative)
+func relativeResults(results []emoji.ProcessResult, root string) []emoji.ProcessResult {
+	relative := make([]emoji.ProcessResult, len(results))
+	for i, result := range results {
+		result.FilePath = relativePath(root, result.FilePath)
+		relative[i] = result
+	}
+	return relative
+}
+
+// relativePath returns path relative to root, or path unchanged if it cannot be made
+// relative. The archive part of a zip:// entry path is made relative too.
+func relativePath(root, path string) string {
+	if rest, ok := strings.CutPrefix(path, "zip://"); ok {
+		archive, entry, _ := strings.Cut(rest, "!")
+		return "zip://" + relativePath(root, archive) + "!" + entry
+	}
+
+	rel, err := filepath.Rel(root, path)
+	switch {
+	case err != nil:
+		return path
+	case rel == ".":
+		return filepath.Base(path) // root is the file itself
+	}
+	return rel
+}
+
+// sortEmojiLists returns a copy of results with each file's emoji list sorted by
+// code point, leaving the processor's results in discovery order.
+func sortEmojiLists(results []emoji.ProcessResult) []emoji.ProcessResult {
+	sorted := make([]emoji.ProcessResult, len(results))
+	for i, result := range results {
+		result.EmojisFound = append([]string(nil), result.EmojisFound...)
+		sort.Strings(result.EmojisFound) // UTF-8 byte order is code point order
+		sorted[i] = result
+	}
+	return sorted
+}
+
+// sortResults returns a copy of results ordered for the report by key, one of the
+// --report-sort values. Ties are broken by path so the order is reproducible.
+func sortResults(results []emoji.ProcessResult, key string) []emoji.ProcessResult {
+	sorted := append([]emoji.ProcessResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch key {
+		case reportSortCount:
+			if a.Occurrences != b.Occurrences {
+				return a.Occurrences > b.Occurrences
+			}
+		case reportSortSavings:
+			if bytesRemoved(a) != bytesRemoved(b) {
+				return bytesRemoved(a) > bytesRemoved(b)
+			}
+		}
+		return a.FilePath < b.FilePath
+	})
+	return sorted
 }
 
 // outputResults handles the output formatting based on results and config
-func outputResults(results []emoji.ProcessResult, config *commandConfig, isStdinContent bool) error {
+func outputResults(results []emoji.ProcessResult, config *commandConfig, isStdinContent bool, cleanedContent string) error {
+	if config.sortEmojis {
+		results = sortEmojiLists(results)
+	}
+	if config.reportSort != "" {
+		results = sortResults(results, config.reportSort)
+	}
+
 	if config.output == "json" {
-		return outputJSON(results, config)
+		return outputJSON(results, config, cleanedContent)
+	}
+
+	stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	if config.asciiSafe {
+		stdout, stderr = asciiSafeWriter{stdout}, asciiSafeWriter{stderr}
+	}
+
+	// --min-emojis and --min-density narrow the report, not the run: summaries still cover all results
+	shown := reportedResults(results, config)
+	if config.output == "html" {
+		return outputHTML(os.Stdout, shown, config.dryRun)
+	}
+	if config.output == outputFormatJUnit {
+		return outputJUnit(os.Stdout, shown, nil)
 	}
 
 	// For stdin content processing, we already output the cleaned content to stdout
 	// So we only need to output the report to stderr (or skip if quiet or no emojis)
 	if isStdinContent {
-		if config.quiet || len(results) == 0 {
-			return nil // No report needed for stdin with quiet mode or no emojis
+		if config.quiet {
+			return nil // No report needed for stdin with quiet mode
+		}
+		if config.output == outputFormatShort {
+			return outputShortResults(stderr, shown)
+		}
+		if len(shown) > 0 {
+			if err := outputDetailedResults(stderr, shown, config.dryRun); err != nil {
+				return err
+			}
 		}
-		return outputDetailedResults(results, config.dryRun, true) // true = output to stderr
+		writeStdinSummary(stderr, results, config.dryRun)
+		return nil
 	}
 
 	// Text output (original behavior for directories and file lists)
@@ -209,93 +1398,456 @@ func outputResults(results []emoji.ProcessResult, config *commandConfig, isStdin
 		return nil // In quiet mode, suppress all output except list-only
 	}
 
+	if config.output == outputFormatShort && !config.listOnly {
+		return outputShortResults(stdout, shown)
+	}
+
 	if len(results) == 0 {
 		if !config.listOnly {
-			fmt.Println("No emojis found in any files.")
+			_, _ = fmt.Fprintln(stdout, "No emojis found in any files.")
 		}
 		return nil
 	}
 
 	if config.listOnly {
-		return outputFileList(results)
+		return outputFileList(stdout, shown, config.print0)
 	}
 
-	return outputDetailedResults(results, config.dryRun, false) // false = output to stdout
+	if len(shown) > 0 {
+		var err error
+		if config.grouped() {
+			err = outputGroupedResults(stdout, shown, config.groupRoots(), config.dryRun)
+		} else {
+			err = outputDetailedResults(stdout, shown, config.dryRun)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.reportFiltered() {
+		writeReportFilterNote(stdout, results, shown, config)
+	}
+	return nil
+}
+
+// reportFiltered reports whether --min-emojis or --min-density limits the files reported
+func (c *commandConfig) reportFiltered() bool {
+	return c.minEmojis > 0 || c.minDensity > 0
+}
+
+// reportedResults returns the results passing --min-emojis and --min-density
+func reportedResults(results []emoji.ProcessResult, config *commandConfig) []emoji.ProcessResult {
+	return filterByMinDensity(filterByMinEmojis(results, config.minEmojis), config.minDensity)
+}
+
+// filterByMinEmojis returns the results with at least minEmojis emoji occurrences,
+// or all results if minEmojis is zero
+func filterByMinEmojis(results []emoji.ProcessResult, minEmojis int) []emoji.ProcessResult {
+	if minEmojis <= 0 {
+		return results
+	}
+
+	var shown []emoji.ProcessResult
+	for _, result := range results {
+		if result.Occurrences >= minEmojis {
+			shown = append(shown, result)
+		}
+	}
+	return shown
+}
+
+// filterByMinDensity returns the results whose emoji density is above minDensity
+// percent, or all results if minDensity is zero
+func filterByMinDensity(results []emoji.ProcessResult, minDensity float64) []emoji.ProcessResult {
+	if minDensity <= 0 {
+		return results
+	}
+
+	var shown []emoji.ProcessResult
+	for _, result := range results {
+		if result.Density > minDensity {
+			shown = append(shown, result)
+		}
+	}
+	return shown
+}
+
+// writeReportFilterNote explains that a report filtered by --min-emojis or
+// --min-density shows only part of the run, and gives the totals for the whole run
+func writeReportFilterNote(out io.Writer, results, shown []emoji.ProcessResult, config *commandConfig) {
+	totalEmojis := 0
+	for _, result := range results {
+		totalEmojis += len(result.EmojisFound)
+	}
+
+	var criteria []string
+	if config.minEmojis > 0 {
+		criteria = append(criteria, fmt.Sprintf("with at least %d emoji occurrence(s)", config.minEmojis))
+	}
+	if config.minDensity > 0 {
+		criteria = append(criteria, fmt.Sprintf("with an emoji density above %g%%", config.minDensity))
+	}
+	_, _ = fmt.Fprintf(out, "Showing %d of %d file(s) %s; the full run found %d emoji(s) in %d file(s).\n",
+		len(shown), len(results), strings.Join(criteria, " and "), totalEmojis, len(results))
+}
+
+// checkResults lists the files a run would change and fails if there are any (for --check).
+// Archive entries are report-only and never rewritten, and protected files and files
+// whose write was refused are never written either, so none of them count as changes.
+// Files whose line endings keep them from being cleaned still hold emojis, so they count.
+func checkResults(cmd *cobra.Command, results []emoji.ProcessResult, config *commandConfig) error {
+	var changed []emoji.ProcessResult
+	for _, result := range results {
+		if result.Protected || result.WriteRefused {
+			continue
+		}
+		if result.Modified || result.LineEndingConflict {
+			changed = append(changed, result)
+		}
+	}
+
+	var err error
+	if config.output == "json" {
+		err = outputJSON(changed, config, "")
+	} else {
+		err = outputFileList(os.Stdout, changed, config.print0)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(changed) > 0 && !config.reportOnly {
+		cmd.SilenceUsage = true // The files are already listed, so usage text would only add noise
+		return fmt.Errorf("%d file(s) would be changed", len(changed))
+	}
+	return nil
+}
+
+// writeStdinSummary writes a one-line summary of stdin content processing
+func writeStdinSummary(out io.Writer, results []emoji.ProcessResult, dryRun bool) {
+	totalEmojis := 0
+	for _, result := range results {
+		totalEmojis += len(result.EmojisFound)
+	}
+
+	switch {
+	case totalEmojis == 0:
+		_, _ = fmt.Fprintln(out, "Summary: no emojis found in stdin")
+	case dryRun:
+		_, _ = fmt.Fprintf(out, "Summary: would remove %d emoji(s) from stdin (dry run)\n", totalEmojis)
+	default:
+		_, _ = fmt.Fprintf(out, "Summary: removed %d emoji(s) from stdin\n", totalEmojis)
+	}
 }
 
-// outputFileList outputs just the file paths (for --list-only)
-func outputFileList(results []emoji.ProcessResult) error {
+// outputFileList outputs just the file paths (for --list-only), one per line or,
+// with print0, each followed by a NUL byte for xargs -0
+func outputFileList(out io.Writer, results []emoji.ProcessResult, print0 bool) error {
+	delimiter := "\n"
+	if print0 {
+		delimiter = "\x00"
+	}
 	for _, result := range results {
-		fmt.Println(result.FilePath)
+		if _, err := fmt.Fprint(out, result.FilePath, delimiter); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // outputDetailedResults outputs detailed results with emoji counts and size changes
-func outputDetailedResults(results []emoji.ProcessResult, dryRun bool, toStderr bool) error {
-	out := os.Stdout
-	if toStderr {
-		out = os.Stderr
+func outputDetailedResults(out io.Writer, results []emoji.ProcessResult, dryRun bool) error {
+	writeReportHeader(out, results, dryRun)
+
+	for _, result := range results {
+		writeFileDetails(out, result, dryRun, "")
+	}
+
+	writeReportTotals(out, results, dryRun)
+	return nil
+}
+
+// outputGroupedResults outputs detailed results nested under their directory (see groupByDirectory) with per-directory subtotals
+func outputGroupedResults(out io.Writer, results []emoji.ProcessResult, roots []string, dryRun bool) error {
+	writeReportHeader(out, results, dryRun)
+
+	for _, group := range groupByDirectory(results, roots) {
+		_, _ = fmt.Fprintf(out, "Directory: %s (%d emoji(s) in %d file(s), %d bytes)\n\n", group.Directory, group.TotalEmojis, len(group.Results), group.TotalBytes)
+		for _, result := range group.Results {
+			writeFileDetails(out, result, dryRun, "  ")
+		}
 	}
 
+	writeReportTotals(out, results, dryRun)
+	return nil
+}
+
+// writeReportHeader writes the opening line of a detailed report
+func writeReportHeader(out io.Writer, results []emoji.ProcessResult, dryRun bool) {
 	if dryRun {
 		_, _ = fmt.Fprintf(out, "DRY RUN: Found emojis in %d file(s):\n\n", len(results))
 	} else {
 		_, _ = fmt.Fprintf(out, "Processed %d file(s) and removed emojis:\n\n", len(results))
 	}
+}
+
+// writeFileDetails writes the report entry for a single file, prefixing each line with indent
+func writeFileDetails(out io.Writer, result emoji.ProcessResult, dryRun bool, indent string) {
+	_, _ = fmt.Fprintf(out, "%sFile: %s\n", indent, result.FilePath)
+	if first := result.FirstEmoji; first.Emoji != "" {
+		_, _ = fmt.Fprintf(out, "%s  First emoji: %s at line %d, column %d", indent, first.Emoji, first.Line, first.Column)
+		if more := len(result.EmojisFound) - 1; more > 0 {
+			_, _ = fmt.Fprintf(out, " (+%d more)", more)
+		}
+		_, _ = fmt.Fprintln(out)
+	} else {
+		_, _ = fmt.Fprintf(out, "%s  Emojis found: %v\n", indent, result.EmojisFound)
+	}
+	if len(result.PreservedEmojis) > 0 {
+		_, _ = fmt.Fprintf(out, "%s  Preserved in code: %v\n", indent, result.PreservedEmojis)
+	}
+	if len(result.Replacements) > 0 {
+		_, _ = fmt.Fprintf(out, "%s  Replaced: %s\n", indent, formatReplacements(result.Replacements))
+	}
+	if len(result.EmojiOnlyLines) > 0 {
+		_, _ = fmt.Fprintf(out, "%s  Emoji-only lines: %s\n", indent, joinInts(result.EmojiOnlyLines))
+	}
+	if result.CleanedSHA256 != "" {
+		_, _ = fmt.Fprintf(out, "%s  Cleaned SHA-256: %s\n", indent, result.CleanedSHA256)
+	}
+	if !result.ModifiedTime.IsZero() {
+		_, _ = fmt.Fprintf(out, "%s  Last modified: %s\n", indent, result.ModifiedTime.Format(time.RFC3339))
+	}
+
+	if result.Protected {
+		_, _ = fmt.Fprintf(out, "%s  Protected: reported only, never modified\n", indent)
+	}
+
+	if result.LineEndingConflict {
+		_, _ = fmt.Fprintf(out, "%s  Line endings: removing emojis would change them, not written\n", indent)
+	}
+
+	if result.WriteRefused {
+		_, _ = fmt.Fprintf(out, "%s  Emptied: cleaned content would be blank, not written\n", indent)
+	} else if result.Emptied {
+		_, _ = fmt.Fprintf(out, "%s  Emptied: cleaned content is blank\n", indent)
+	}
+
+	if result.Modified {
+		if dryRun || result.Protected || result.WriteRefused {
+			_, _ = fmt.Fprintf(out, "%s  Would reduce size: %d → %d bytes\n", indent, result.OriginalSize, result.NewSize)
+		} else {
+			_, _ = fmt.Fprintf(out, "%s  Size changed: %d → %d bytes\n", indent, result.OriginalSize, result.NewSize)
+		}
+	}
+	_, _ = fmt.Fprintln(out)
+}
+
+// formatReplacements formats an emoji-to-replacement map as a comma-separated list of
+// "emoji → replacement" pairs, sorted by emoji
+func formatReplacements(replacements map[string]string) string {
+	emojis := make([]string, 0, len(replacements))
+	for e := range replacements {
+		emojis = append(emojis, e)
+	}
+	sort.Strings(emojis)
 
+	pairs := make([]string, len(emojis))
+	for i, e := range emojis {
+		pairs[i] = fmt.Sprintf("%s → %q", e, replacements[e])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// joinInts formats numbers as a comma-separated list
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeReportTotals writes the closing totals of a detailed report
+func writeReportTotals(out io.Writer, results []emoji.ProcessResult, dryRun bool) {
 	totalEmojis := 0
 	for _, result := range results {
-		_, _ = fmt.Fprintf(out, "File: %s\n", result.FilePath)
-		_, _ = fmt.Fprintf(out, "  Emojis found: %v\n", result.EmojisFound)
 		totalEmojis += len(result.EmojisFound)
-
-		if result.Modified {
-			if dryRun {
-				_, _ = fmt.Fprintf(out, "  Would reduce size: %d → %d bytes\n", result.OriginalSize, result.NewSize)
-			} else {
-				_, _ = fmt.Fprintf(out, "  Size changed: %d → %d bytes\n", result.OriginalSize, result.NewSize)
-			}
-		}
-		_, _ = fmt.Fprintln(out)
 	}
 
 	if dryRun {
-		_, _ = fmt.Fprintf(out, "Total: Would remove %d emoji(s) from %d file(s)\n", totalEmojis, len(results))
+		_, _ = fmt.Fprintf(out, "Total: Would remove %d emoji(s) from %d file(s), %d bytes\n", totalEmojis, len(results), totalBytesRemoved(results))
 		_, _ = fmt.Fprintln(out, "Run with --no-dry-run to actually remove emojis.")
 	} else {
-		_, _ = fmt.Fprintf(out, "Total: Removed %d emoji(s) from %d file(s)\n", totalEmojis, len(results))
+		_, _ = fmt.Fprintf(out, "Total: Removed %d emoji(s) from %d file(s), %d bytes\n", totalEmojis, len(results), totalBytesRemoved(results))
+	}
+}
+
+// bytesRemoved returns how many bytes cleaning shrinks a file by, or would in a dry
+// run. Replacements longer than the emojis they replace make it negative.
+func bytesRemoved(result emoji.ProcessResult) int64 {
+	if !result.Modified {
+		return 0
 	}
+	return result.OriginalSize - result.NewSize
+}
 
-	return nil
+// totalBytesRemoved sums bytesRemoved over results
+func totalBytesRemoved(results []emoji.ProcessResult) int64 {
+	var total int64
+	for _, result := range results {
+		total += bytesRemoved(result)
+	}
+	return total
+}
+
+// directoryGroup holds the results for files sharing a parent directory
+type directoryGroup struct {
+	Directory   string
+	Results     []emoji.ProcessResult
+	TotalEmojis int
+	TotalBytes  int64
+}
+
+// groupByDirectory groups results by parent directory, or by the longest of roots
+// containing them when roots is set, sorted by directory name. Files keep their
+// original order within each group.
+func groupByDirectory(results []emoji.ProcessResult, roots []string) []directoryGroup {
+	index := make(map[string]int)
+	var groups []directoryGroup
+
+	for _, result := range results {
+		dir := resultDirectory(result.FilePath, roots)
+		i, ok := index[dir]
+		if !ok {
+			i = len(groups)
+			index[dir] = i
+			groups = append(groups, directoryGroup{Directory: dir})
+		}
+		groups[i].Results = append(groups[i].Results, result)
+		groups[i].TotalEmojis += len(result.EmojisFound)
+		groups[i].TotalBytes += bytesRemoved(result)
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a].Directory < groups[b].Directory
+	})
+	return groups
+}
+
+// resultDirectory returns the directory a result is grouped under: the longest of
+// roots containing path, or its parent directory if there is none
+func resultDirectory(path string, roots []string) string {
+	dir := ""
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(dir) {
+			dir = root
+		}
+	}
+	if dir == "" {
+		return filepath.Dir(path)
+	}
+	return dir
+}
+
+// grouped reports whether the report is grouped by directory, as it is by default
+// with --dirs-from
+func (c *commandConfig) grouped() bool {
+	return c.groupBy == groupByDir || len(c.dirs) > 0
+}
+
+// groupRoots returns the directories results are grouped under: the --dirs-from
+// directories, unless --group-by dir asks for parent directories instead
+func (c *commandConfig) groupRoots() []string {
+	if c.groupBy == groupByDir {
+		return nil
+	}
+	return c.dirs
 }
 
-// processFilePathsFromStdin reads file paths from stdin and processes each file
-func processFilePathsFromStdin(processor *emoji.FileProcessor, dryRun bool) ([]emoji.ProcessResult, error) {
+// processFilePathsFromStdin reads file paths from stdin and processes each file. With
+// manifest set, each line may carry per-file directives after a tab (see parseManifestLine).
+func processFilePathsFromStdin(processor *emoji.FileProcessor, dryRun bool, manifest bool, logger *slog.Logger) ([]emoji.ProcessResult, error) {
 	var results []emoji.ProcessResult
 	scanner := bufio.NewScanner(os.Stdin)
+	globalDetector := processor.Detector
+	allowByExt := processor.AllowByExtension
+	defer func() {
+		processor.Detector = globalDetector
+		processor.AllowByExtension = allowByExt
+	}()
 
 	for scanner.Scan() {
 		filePath := strings.TrimSpace(scanner.Text())
+		if manifest {
+			var directives *manifestDirectives
+			var err error
+			filePath, directives, err = parseManifestLine(scanner.Text())
+			if err != nil {
+				logger.Warn("skipping manifest line", "error", err)
+				continue
+			}
+			processor.Detector = directives.detector(globalDetector)
+			// An allow= directive overrides the allow file's extension sections too
+			processor.AllowByExtension = allowByExt
+			if directives != nil && directives.hasAllowed {
+				processor.AllowByExtension = nil
+			}
+		}
 		if filePath == "" {
 			continue
 		}
 
 		// Check if file exists
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Warning: file does not exist: %s\n", filePath)
+		info, err := os.Stat(filePath)
+		if os.IsNotExist(err) {
+			logger.Warn("file does not exist", "path", filePath)
+			continue
+		}
+		if info != nil && processor.IsStale(info.ModTime()) {
+			continue
+		}
+		if info != nil && processor.SkipForSize(filePath, info.Size()) {
+			continue
+		}
+
+		if processor.IsScannableZip(filePath) {
+			zipResults, err := processor.ScanZipArchive(filePath)
+			if err != nil {
+				logger.Warn("failed to process file", "path", filePath, "error", err)
+				continue
+			}
+			results = append(results, zipResults...)
+			if processor.LimitReached(len(results)) {
+				results = results[:processor.Limit]
+				break
+			}
 			continue
 		}
 
 		result, err := processor.ProcessFile(filePath, dryRun)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to process %s: %v\n", filePath, err)
+			logger.Warn("failed to process file", "path", filePath, "error", err)
 			continue
 		}
+		if processor.OnFileProcessed != nil {
+			processor.OnFileProcessed(filePath)
+		}
+		if processor.IncludeModTime && info != nil {
+			result.ModifiedTime = info.ModTime()
+		}
 
 		// Only include files that actually had emojis
 		if len(result.EmojisFound) > 0 {
 			results = append(results, result)
+			if processor.LimitReached(len(results)) {
+				break
+			}
 		}
 	}
 
@@ -306,8 +1858,8 @@ func processFilePathsFromStdin(processor *emoji.FileProcessor, dryRun bool) ([]e
 	return results, nil
 }
 
-// processContentFromStdin reads content from stdin and processes it directly
-func processContentFromStdin(processor *emoji.FileProcessor, dryRun bool) ([]emoji.ProcessResult, error) {
+// processContentFromStdin reads content from stdin and processes it directly, writing cleaned content to out
+func processContentFromStdin(processor *emoji.FileProcessor, dryRun bool, out io.Writer) ([]emoji.ProcessResult, error) {
 	// Read all content from stdin
 	scanner := bufio.NewScanner(os.Stdin)
 	var content strings.Builder
@@ -330,7 +1882,7 @@ func processContentFromStdin(processor *emoji.FileProcessor, dryRun bool) ([]emo
 	contentStr = strings.TrimSuffix(contentStr, "\n")
 
 	// Use the processor's detector which has allowed emojis configured
-	emojis := processor.Detector.FindEmojis(contentStr)
+	cleanedContent, emojis := processor.CleanAndReport(contentStr)
 
 	result := emoji.ProcessResult{
 		FilePath:     "<stdin>",
@@ -338,25 +1890,84 @@ func processContentFromStdin(processor *emoji.FileProcessor, dryRun bool) ([]emo
 		OriginalSize: int64(len(contentStr)),
 		Modified:     len(emojis) > 0,
 	}
+	if processor.CountOccurrences {
+		result.Occurrences = processor.Detector.Count(contentStr)
+	}
 
 	if len(emojis) == 0 {
 		return []emoji.ProcessResult{}, nil
 	}
 
-	// Process the content (remove emojis)
-	cleanedContent := processor.Detector.RemoveEmojis(contentStr)
 	result.NewSize = int64(len(cleanedContent))
 
 	if !dryRun {
-		// Output the cleaned content to stdout
-		fmt.Print(cleanedContent)
+		// Output the cleaned content
+		_, _ = fmt.Fprint(out, cleanedContent)
 	}
 
 	return []emoji.ProcessResult{result}, nil
 }
 
-// outputJSON outputs results in JSON format
-func outputJSON(results []emoji.ProcessResult, config *commandConfig) error {
+// processTextArgument cleans the --text value, printing the cleaned text to stdout and findings to stderr
+func processTextArgument(processor *emoji.FileProcessor, config *commandConfig) error {
+	cleaned, emojis := processor.CleanAndReport(config.text)
+
+	results := []emoji.ProcessResult{}
+	if len(emojis) > 0 {
+		result := emoji.ProcessResult{
+			FilePath:     "<text>",
+			EmojisFound:  emojis,
+			OriginalSize: int64(len(config.text)),
+			NewSize:      int64(len(cleaned)),
+			Modified:     true,
+		}
+		if processor.CountOccurrences {
+			result.Occurrences = processor.Detector.Count(config.text)
+		}
+		results = append(results, result)
+	}
+
+	if config.output == "json" {
+		return outputJSON(results, config, cleaned)
+	}
+
+	stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	if config.asciiSafe {
+		stdout, stderr = asciiSafeWriter{stdout}, asciiSafeWriter{stderr}
+	}
+
+	fmt.Fprintln(stdout, cleaned)
+
+	if !config.quiet {
+		if len(emojis) > 0 {
+			fmt.Fprintf(stderr, "Removed %d emoji(s): %v\n", len(emojis), emojis)
+		} else {
+			fmt.Fprintln(stderr, "No emojis found.")
+		}
+	}
+	return nil
+}
+
+// processInPlace cleans the file named by --in-place-from and, with --no-dry-run,
+// replaces it atomically. This avoids the shell redirect in
+// "emoji-sad - < file > file", which truncates the file before it is read.
+func processInPlace(processor *emoji.FileProcessor, config *commandConfig) error {
+	processor.AtomicWrites = true
+	result, err := processor.ProcessFile(config.inPlaceFrom, config.dryRun)
+	if err != nil {
+		return err
+	}
+
+	// Files left unwritten for their line endings still hold emojis to report
+	results := []emoji.ProcessResult{}
+	if len(result.EmojisFound) > 0 || result.LineEndingConflict {
+		results = append(results, result)
+	}
+	return outputResults(results, config, false, "")
+}
+
+// outputJSON outputs results in JSON format, embedding cleaned stdin content if any
+func outputJSON(results []emoji.ProcessResult, config *commandConfig, cleanedContent string) error {
 	var mode string
 	if config.listOnly {
 		mode = "list"
@@ -375,19 +1986,40 @@ func outputJSON(results []emoji.ProcessResult, config *commandConfig) error {
 		Summary: JSONSummary{
 			TotalFiles:  len(results),
 			TotalEmojis: totalEmojis,
+			TotalBytes:  totalBytesRemoved(results),
 			DryRun:      config.dryRun,
 			Mode:        mode,
+			Stats:       computeStats(results),
 		},
-		Files: make([]JSONFileInfo, 0, len(results)),
+		Files:          make([]JSONFileInfo, 0, len(results)),
+		CleanedContent: cleanedContent,
+	}
+
+	shown := reportedResults(results, config)
+	if config.reportFiltered() {
+		shownFiles := len(shown)
+		output.Summary.MinEmojis = config.minEmojis
+		output.Summary.MinDensity = config.minDensity
+		output.Summary.ShownFiles = &shownFiles
 	}
 
 	// Convert results to JSON format
-	for _, result := range results {
+	for _, result := range shown {
 		fileInfo := JSONFileInfo{
-			FilePath:     result.FilePath,
-			EmojisFound:  result.EmojisFound,
-			OriginalSize: result.OriginalSize,
-			Modified:     result.Modified,
+			FilePath:           result.FilePath,
+			EmojisFound:        result.EmojisFound,
+			OriginalSize:       result.OriginalSize,
+			Modified:           result.Modified,
+			EmojiOnlyLines:     result.EmojiOnlyLines,
+			CleanedSHA256:      result.CleanedSHA256,
+			Occurrences:        result.Occurrences,
+			Protected:          result.Protected,
+			Emptied:            result.Emptied,
+			WriteRefused:       result.WriteRefused,
+			LineEndingConflict: result.LineEndingConflict,
+			Preserved:          result.PreservedEmojis,
+			Replacements:       result.Replacements,
+			Density:            result.Density,
 		}
 
 		// Only include new size if file was modified
@@ -395,15 +2027,51 @@ func outputJSON(results []emoji.ProcessResult, config *commandConfig) error {
 			fileInfo.NewSize = result.NewSize
 		}
 
+		// Only include modification time if it was recorded
+		if !result.ModifiedTime.IsZero() {
+			modTime := result.ModifiedTime
+			fileInfo.ModifiedTime = &modTime
+		}
+
 		output.Files = append(output.Files, fileInfo)
 	}
 
+	if config.grouped() {
+		for _, group := range groupByDirectory(results, config.groupRoots()) {
+			output.ByDirectory = append(output.ByDirectory, JSONDirectorySummary{
+				Directory:   group.Directory,
+				TotalFiles:  len(group.Results),
+				TotalEmojis: group.TotalEmojis,
+				TotalBytes:  group.TotalBytes,
+			})
+		}
+	}
+
 	// Marshal and output JSON
-	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	jsonBytes, err := json.MarshalIndent(output, "", config.jsonIndent)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON output: %w", err)
 	}
+	jsonBytes = append(jsonBytes, '\n')
+	if config.asciiSafe {
+		jsonBytes = asciiSafeJSON(jsonBytes)
+	}
+
+	if config.gzipOutput {
+		return writeGzip(os.Stdout, jsonBytes)
+	}
+	_, err = os.Stdout.Write(jsonBytes)
+	return err
+}
 
-	fmt.Println(string(jsonBytes))
+// writeGzip writes data to out as a complete gzip stream
+func writeGzip(out io.Writer, data []byte) error {
+	gz := gzip.NewWriter(out)
+	if _, err := gz.Write(data); err != nil {
+		return fmt.Errorf("failed to write gzip output: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish gzip output: %w", err)
+	}
 	return nil
 }
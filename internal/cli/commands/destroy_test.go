@@ -1,14 +1,154 @@
 package commands
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
+
+	"emoji-search-and-destroy/pkg/emoji"
 
 	"github.com/spf13/cobra"
 )
 
+// newTestCommand builds a command with the same flags as the root command.
+func newTestCommand(noDryRun bool) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("no-dry-run", noDryRun, "")
+	cmd.Flags().BoolP("list-only", "l", false, "")
+	cmd.Flags().Bool("print0", false, "")
+	cmd.Flags().Bool("relative", false, "")
+	cmd.Flags().Bool("ascii-safe", false, "")
+	cmd.Flags().String("structured", "", "")
+	cmd.Flags().Bool("markdown-aware", false, "")
+	cmd.Flags().StringSlice("exclude", []string{}, "")
+	cmd.Flags().StringSlice("exclude-regex", []string{}, "")
+	cmd.Flags().StringSlice("protect", []string{}, "")
+	cmd.Flags().String("checkpoint", "", "")
+	cmd.Flags().Bool("bidi-cleanup", false, "")
+	cmd.Flags().Bool("group-clusters", false, "")
+	cmd.Flags().Bool("bmp-only", false, "")
+	cmd.Flags().StringSlice("never", []string{}, "")
+	cmd.Flags().Bool("git-tracked-only", false, "")
+	cmd.Flags().Bool("warn-on-empty", false, "")
+	cmd.Flags().Bool("refuse-empty", false, "")
+	cmd.Flags().StringP("output", "o", "text", "")
+	cmd.Flags().String("text", "", "")
+	cmd.Flags().Bool("files-from-stdin", false, "")
+	cmd.Flags().String("dirs-from", "", "")
+	cmd.Flags().BoolP("quiet", "q", false, "")
+	cmd.Flags().StringP("allow-file", "a", "", "")
+	cmd.Flags().Bool("require-allow-file", false, "")
+	cmd.Flags().Bool("scan-gz", false, "")
+	cmd.Flags().Bool("scan-zip", false, "")
+	cmd.Flags().Bool("assert-no-writes", false, "")
+	cmd.Flags().Bool("trace", false, "")
+	cmd.Flags().Bool("squeeze-blank-lines", false, "")
+	cmd.Flags().Bool("dedupe-across-run", false, "")
+	cmd.Flags().Bool("include-mtime", false, "")
+	cmd.Flags().Duration("since-mtime", 0, "")
+	cmd.Flags().Int("limit", 0, "")
+	cmd.Flags().Int("workers", 0, "")
+	cmd.Flags().Int("max-open-files", 0, "")
+	cmd.Flags().String("whitespace", "keep", "")
+	cmd.Flags().Bool("collapse-between", false, "")
+	cmd.Flags().Bool("decode-html-entities", false, "")
+	cmd.Flags().String("replace-map", "", "")
+	cmd.Flags().Bool("check", false, "")
+	cmd.Flags().String("report-sort", "", "")
+	cmd.Flags().Bool("report-only", false, "")
+	cmd.Flags().Bool("staged", false, "")
+	cmd.Flags().Bool("preview", false, "")
+	cmd.Flags().Bool("decode-escapes", false, "")
+	cmd.Flags().Bool("manifest", false, "")
+	cmd.Flags().Bool("emoji-only-lines", false, "")
+	cmd.Flags().Bool("delete-emoji-only-lines", false, "")
+	cmd.Flags().Bool("gzip-output", false, "")
+	cmd.Flags().Bool("hash-cleaned", false, "")
+	cmd.Flags().Bool("no-skip-binary", false, "")
+	cmd.Flags().Bool("exclude-binary-by-mime", false, "")
+	cmd.Flags().String("column-mode", "rune", "")
+	cmd.Flags().Bool("i-understand", false, "")
+	cmd.Flags().BoolP("yes", "y", false, "")
+	cmd.Flags().Bool("sort-emojis", false, "")
+	cmd.Flags().String("in-place-from", "", "")
+	cmd.Flags().String("json-indent", "2", "")
+	cmd.Flags().StringSlice("allow", []string{}, "")
+	cmd.Flags().Int("min-emojis", 0, "")
+	cmd.Flags().Float64("min-density", 0, "")
+	cmd.Flags().String("log-format", "text", "")
+	cmd.Flags().String("log-level", "warn", "")
+	cmd.Flags().String("min-file-size", "", "")
+	cmd.Flags().String("max-file-size", "", "")
+	cmd.Flags().Int("max-lines", 0, "")
+	cmd.Flags().Bool("skip-symlinks", false, "")
+	cmd.Flags().String("input-charset", "", "")
+	cmd.Flags().Bool("ascii", false, "")
+	cmd.Flags().String("group-by", "", "")
+	return cmd
+}
+
+// captureStdout runs fn and returns everything it wrote to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	fn()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+// captureStderr runs fn and returns everything it wrote to stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	oldStderr := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = w
+
+	fn()
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+// withStdin runs fn with os.Stdin reading the given content.
+func withStdin(t *testing.T, content string, fn func()) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.WriteString(content)
+	_ = w.Close()
+
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+
+	fn()
+}
+
 func TestDestroyEmojis(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -131,14 +271,7 @@ func TestDestroyEmojis(t *testing.T) {
 			defer cleanup()
 
 			// Create command
-			cmd := &cobra.Command{}
-			cmd.Flags().Bool("no-dry-run", tt.noDryRun, "")
-			cmd.Flags().BoolP("list-only", "l", false, "")
-			cmd.Flags().StringSlice("exclude", []string{}, "")
-			cmd.Flags().StringP("output", "o", "text", "")
-			cmd.Flags().Bool("files-from-stdin", false, "")
-			cmd.Flags().BoolP("quiet", "q", false, "")
-			cmd.Flags().StringP("allow-file", "a", "", "")
+			cmd := newTestCommand(tt.noDryRun)
 
 			// Capture output
 			oldStdout := os.Stdout
@@ -197,3 +330,1728 @@ func TestDestroyEmojisEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestParseFlagsExcludeRegex(t *testing.T) {
+	t.Run("valid regex is compiled", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("exclude-regex", ".*/testdata/.*")
+
+		config, err := parseFlags(cmd)
+		if err != nil {
+			t.Fatalf("parseFlags() error = %v", err)
+		}
+		if len(config.excludeRegexps) != 1 {
+			t.Fatalf("Expected 1 compiled regex, got %d", len(config.excludeRegexps))
+		}
+	})
+
+	t.Run("invalid regex errors", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("exclude-regex", "([unclosed")
+
+		if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid exclude-regex") {
+			t.Errorf("Expected invalid exclude-regex error, got %v", err)
+		}
+	})
+}
+
+func TestOutputJSONModifiedTime(t *testing.T) {
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	results := []emoji.ProcessResult{
+		{FilePath: "a.txt", EmojisFound: []string{"😊"}, ModifiedTime: mtime},
+		{FilePath: "<stdin>", EmojisFound: []string{"🚀"}},
+	}
+
+	output := captureStdout(t, func() {
+		_ = outputJSON(results, &commandConfig{output: "json"}, "")
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if parsed.Files[0].ModifiedTime == nil || !parsed.Files[0].ModifiedTime.Equal(mtime) {
+		t.Errorf("modified_time = %v, want %v", parsed.Files[0].ModifiedTime, mtime)
+	}
+	if parsed.Files[1].ModifiedTime != nil {
+		t.Errorf("<stdin> should have no modified_time, got %v", parsed.Files[1].ModifiedTime)
+	}
+}
+
+func TestParseFlagsLimit(t *testing.T) {
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("limit", "-1")
+
+	if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid limit") {
+		t.Errorf("Expected invalid limit error, got %v", err)
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	invalid := []struct {
+		name  string
+		flags map[string]string
+		want  string
+	}{
+		{"negative workers", map[string]string{"workers": "-1"}, "invalid workers"},
+		{"negative max open files", map[string]string{"workers": "2", "max-open-files": "-1"}, "invalid max-open-files"},
+		{"max open files alone", map[string]string{"max-open-files": "2"}, "--max-open-files requires --workers"},
+		{"staged", map[string]string{"workers": "2", "staged": "true"}, "--workers cannot be used with --staged"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newTestCommand(false)
+			for name, value := range tt.flags {
+				_ = cmd.Flags().Set(name, value)
+			}
+			if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected %q error, got %v", tt.want, err)
+			}
+		})
+	}
+
+	dir := t.TempDir()
+	for i := 0; i < 6; i++ {
+		_ = os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), []byte("Hello \U0001F60A"), 0600)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "plain.txt"), []byte("Hello"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("workers", "4")
+	_ = cmd.Flags().Set("max-open-files", "2")
+	_ = cmd.Flags().Set("output", "json")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if parsed.Summary.TotalFiles != 6 {
+		t.Errorf("total_files = %d, want 6", parsed.Summary.TotalFiles)
+	}
+	for i := 0; i < 6; i++ {
+		content, _ := os.ReadFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i))) // #nosec G304 -- test file in a temp directory
+		if string(content) != "Hello " {
+			t.Errorf("file%d.txt = %q, want cleaned", i, content)
+		}
+	}
+}
+
+func TestDestroyEmojisJSONStdoutIsolation(t *testing.T) {
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("output", "json")
+
+	// Feed stdin content that will be cleaned in no-dry-run mode
+	var runErr error
+	output := captureStdout(t, func() {
+		withStdin(t, "Hello 😊 World", func() {
+			runErr = DestroyEmojis(cmd, []string{"-"})
+		})
+	})
+	if runErr != nil {
+		t.Fatalf("DestroyEmojis() error = %v", runErr)
+	}
+
+	// stdout must hold exactly one JSON value and nothing else
+	decoder := json.NewDecoder(strings.NewReader(output))
+	var parsed JSONOutput
+	if err := decoder.Decode(&parsed); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, output)
+	}
+	if decoder.More() {
+		t.Errorf("stdout contains more than one JSON value: %s", output)
+	}
+	if parsed.CleanedContent != "Hello  World" {
+		t.Errorf("cleaned_content = %q, want %q", parsed.CleanedContent, "Hello  World")
+	}
+}
+
+func TestParseFlagsWhitespace(t *testing.T) {
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("whitespace", "squash")
+
+	if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid whitespace policy") {
+		t.Errorf("Expected invalid whitespace policy error, got %v", err)
+	}
+}
+
+func TestParseFlagsCollapseBetween(t *testing.T) {
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("collapse-between", "true")
+
+	config, err := parseFlags(cmd)
+	if err != nil {
+		t.Fatalf("parseFlags() error = %v", err)
+	}
+	if config.whitespace != emoji.WhitespaceCollapseBetween {
+		t.Errorf("whitespace = %q, want %q", config.whitespace, emoji.WhitespaceCollapseBetween)
+	}
+
+	_ = cmd.Flags().Set("whitespace", "collapse-both")
+	if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "--collapse-between cannot be used with --whitespace collapse-both") {
+		t.Errorf("Expected conflicting whitespace error, got %v", err)
+	}
+}
+
+func TestGroupByDirectory(t *testing.T) {
+	dir, _ := os.MkdirTemp("", "test_group_by")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	_ = os.MkdirAll(filepath.Join(dir, "api"), 0750)
+	_ = os.MkdirAll(filepath.Join(dir, "web"), 0750)
+	_ = os.WriteFile(filepath.Join(dir, "api", "a.txt"), []byte("one 😊"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "api", "b.txt"), []byte("two 🚀 ✨"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "web", "c.txt"), []byte("three 🎉"), 0600)
+
+	t.Run("text output nests files under directories", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("group-by", "dir")
+
+		output := captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+
+		apiHeader := "Directory: " + filepath.Join(dir, "api") + " (3 emoji(s) in 2 file(s), 11 bytes)"
+		webHeader := "Directory: " + filepath.Join(dir, "web") + " (1 emoji(s) in 1 file(s), 4 bytes)"
+		if !strings.Contains(output, apiHeader) || !strings.Contains(output, webHeader) {
+			t.Errorf("Missing directory subtotals in output:\n%s", output)
+		}
+		if strings.Index(output, apiHeader) > strings.Index(output, webHeader) {
+			t.Error("Directories should be sorted")
+		}
+		if !strings.Contains(output, "  File: "+filepath.Join(dir, "api", "a.txt")) {
+			t.Errorf("Files should be indented under their directory:\n%s", output)
+		}
+		if !strings.Contains(output, "Total: Would remove 4 emoji(s) from 3 file(s), 15 bytes") {
+			t.Errorf("Total should include the bytes removed:\n%s", output)
+		}
+	})
+
+	t.Run("json output adds by_directory", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("group-by", "dir")
+		_ = cmd.Flags().Set("output", "json")
+
+		output := captureStdout(t, func() {
+			_ = DestroyEmojis(cmd, []string{dir})
+		})
+
+		var parsed JSONOutput
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		expected := []JSONDirectorySummary{
+			{Directory: filepath.Join(dir, "api"), TotalFiles: 2, TotalEmojis: 3, TotalBytes: 11},
+			{Directory: filepath.Join(dir, "web"), TotalFiles: 1, TotalEmojis: 1, TotalBytes: 4},
+		}
+		if len(parsed.ByDirectory) != 2 || parsed.ByDirectory[0] != expected[0] || parsed.ByDirectory[1] != expected[1] {
+			t.Errorf("by_directory = %+v, want %+v", parsed.ByDirectory, expected)
+		}
+
+		var sum int64
+		for _, group := range parsed.ByDirectory {
+			sum += group.TotalBytes
+		}
+		if sum != parsed.Summary.TotalBytes || sum != 15 {
+			t.Errorf("Directory bytes sum to %d, want the summary total %d (15)", sum, parsed.Summary.TotalBytes)
+		}
+	})
+
+	t.Run("invalid group-by value", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("group-by", "owner")
+		if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid group-by") {
+			t.Errorf("Expected invalid group-by error, got %v", err)
+		}
+	})
+}
+
+func TestDirsFrom(t *testing.T) {
+	dir := t.TempDir()
+	api := filepath.Join(dir, "api")
+	web := filepath.Join(dir, "web")
+	missing := filepath.Join(dir, "missing")
+	_ = os.MkdirAll(filepath.Join(api, "nested"), 0750)
+	_ = os.MkdirAll(web, 0750)
+	_ = os.WriteFile(filepath.Join(api, "a.txt"), []byte("one 😊"), 0600)
+	_ = os.WriteFile(filepath.Join(api, "nested", "b.txt"), []byte("two 🚀"), 0600)
+	_ = os.WriteFile(filepath.Join(web, "c.txt"), []byte("three 🎉"), 0600)
+
+	dirsFile := filepath.Join(dir, "dirs.txt")
+	_ = os.WriteFile(dirsFile, []byte("# nightly\n"+api+"\n\n"+missing+"\n"+web+"\n"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("dirs-from", dirsFile)
+	_ = cmd.Flags().Set("output", "json")
+
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, nil); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+	})
+	if !strings.Contains(stderr, "skipping directory") || !strings.Contains(stderr, missing) {
+		t.Errorf("Expected a warning about %s, got %q", missing, stderr)
+	}
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if parsed.Summary.TotalFiles != 3 {
+		t.Errorf("total_files = %d, want 3", parsed.Summary.TotalFiles)
+	}
+	expected := []JSONDirectorySummary{
+		{Directory: api, TotalFiles: 2, TotalEmojis: 2, TotalBytes: 8},
+		{Directory: web, TotalFiles: 1, TotalEmojis: 1, TotalBytes: 4},
+	}
+	if len(parsed.ByDirectory) != 2 || parsed.ByDirectory[0] != expected[0] || parsed.ByDirectory[1] != expected[1] {
+		t.Errorf("by_directory = %+v, want %+v", parsed.ByDirectory, expected)
+	}
+
+	t.Run("limit covers all directories", func(t *testing.T) {
+		root := t.TempDir()
+		files := map[string]string{
+			"first/a.txt":  "a 😊",
+			"first/b.txt":  "b 😊",
+			"second/c.txt": "c 😊",
+			"second/d.txt": "d 😊",
+			"third/e.txt":  "e 😊",
+		}
+		for name, content := range files {
+			_ = os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0750)
+			_ = os.WriteFile(filepath.Join(root, name), []byte(content), 0600)
+		}
+		limitDirs := filepath.Join(root, "dirs.txt")
+		_ = os.WriteFile(limitDirs, []byte(filepath.Join(root, "first")+"\n"+filepath.Join(root, "second")+"\n"+filepath.Join(root, "third")+"\n"), 0600)
+
+		cmd := newTestCommand(true)
+		_ = cmd.Flags().Set("dirs-from", limitDirs)
+		_ = cmd.Flags().Set("limit", "3")
+		_ = cmd.Flags().Set("output", "json")
+		output := captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, nil); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+
+		var parsed JSONOutput
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		if parsed.Summary.TotalFiles != 3 {
+			t.Errorf("total_files = %d, want 3", parsed.Summary.TotalFiles)
+		}
+
+		// Only the reported files were rewritten
+		cleaned := 0
+		for name := range files {
+			if content, _ := os.ReadFile(filepath.Join(root, name)); !strings.Contains(string(content), "😊") {
+				cleaned++
+			}
+		}
+		if cleaned != 3 {
+			t.Errorf("Expected 3 files cleaned on disk, got %d", cleaned)
+		}
+		if content, _ := os.ReadFile(filepath.Join(root, "third/e.txt")); string(content) != "e 😊" {
+			t.Errorf("Expected the third directory to be left alone, got %q", content)
+		}
+	})
+
+	t.Run("rejects a directory argument", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("dirs-from", dirsFile)
+		if err := DestroyEmojis(cmd, []string{dir}); err == nil || !strings.Contains(err.Error(), "--dirs-from") {
+			t.Errorf("Expected a --dirs-from error, got %v", err)
+		}
+	})
+}
+
+func TestParseFlagsRequireAllowFile(t *testing.T) {
+	dir, _ := os.MkdirTemp("", "test_require_allow")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	t.Run("missing default allow file without flag", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		if _, err := parseFlags(cmd); err != nil {
+			t.Errorf("parseFlags() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("missing default allow file with flag", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("require-allow-file", "true")
+		if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "allow file is required") {
+			t.Errorf("Expected required allow file error, got %v", err)
+		}
+	})
+
+	t.Run("missing explicit allow file with flag", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("require-allow-file", "true")
+		_ = cmd.Flags().Set("allow-file", "missing.txt")
+		if _, err := parseFlags(cmd); err == nil {
+			t.Error("Expected error for missing explicit allow file")
+		}
+	})
+
+	t.Run("present default allow file with flag", func(t *testing.T) {
+		_ = os.WriteFile(defaultAllowFile, []byte("✅\n"), 0600)
+		defer func() { _ = os.Remove(defaultAllowFile) }()
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("require-allow-file", "true")
+		config, err := parseFlags(cmd)
+		if err != nil {
+			t.Fatalf("parseFlags() error = %v", err)
+		}
+		if len(config.allowedEmojis) != 1 || config.allowedEmojis[0] != "✅" {
+			t.Errorf("allowedEmojis = %v, want [✅]", config.allowedEmojis)
+		}
+	})
+}
+
+func TestDestroyEmojisText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantStdout string
+	}{
+		{"with emojis", "Hello 😊 World 🚀", "Hello  World \n"},
+		{"without emojis", "Hello World", "Hello World\n"},
+		{"empty text", "", "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newTestCommand(false)
+			_ = cmd.Flags().Set("text", tt.text)
+
+			output := captureStdout(t, func() {
+				if err := DestroyEmojis(cmd, []string{}); err != nil {
+					t.Errorf("DestroyEmojis() error = %v", err)
+				}
+			})
+			if output != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", output, tt.wantStdout)
+			}
+		})
+	}
+
+	t.Run("respects allow file", func(t *testing.T) {
+		dir, _ := os.MkdirTemp("", "test_text_allow")
+		defer func() { _ = os.RemoveAll(dir) }()
+		allowFile := filepath.Join(dir, "allow.txt")
+		_ = os.WriteFile(allowFile, []byte("✅\n"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("text", "✅ done ❌ failed")
+		_ = cmd.Flags().Set("allow-file", allowFile)
+
+		output := captureStdout(t, func() {
+			_ = DestroyEmojis(cmd, []string{})
+		})
+		if output != "✅ done  failed\n" {
+			t.Errorf("stdout = %q, want %q", output, "✅ done  failed\n")
+		}
+	})
+
+	t.Run("bmp only", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("text", "✅ done 😀 happy")
+		_ = cmd.Flags().Set("bmp-only", "true")
+
+		output := captureStdout(t, func() {
+			_ = DestroyEmojis(cmd, []string{})
+		})
+		if output != " done 😀 happy\n" {
+			t.Errorf("stdout = %q, want %q", output, " done 😀 happy\n")
+		}
+	})
+
+	t.Run("ascii safe", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("text", "Café 😊")
+		_ = cmd.Flags().Set("ascii-safe", "true")
+
+		var output string
+		stderr := captureStderr(t, func() {
+			output = captureStdout(t, func() {
+				_ = DestroyEmojis(cmd, []string{})
+			})
+		})
+		if output != "CafU+00E9 \n" {
+			t.Errorf("stdout = %q, want %q", output, "CafU+00E9 \n")
+		}
+		if !strings.Contains(stderr, "U+1F60A") || strings.Contains(stderr, "😊") {
+			t.Errorf("Expected ASCII-only findings on stderr, got %q", stderr)
+		}
+	})
+
+	t.Run("unsupported output", func(t *testing.T) {
+		for _, format := range []string{"html", "junit", "short", "tar"} {
+			cmd := newTestCommand(false)
+			_ = cmd.Flags().Set("text", "Hello 😊")
+			_ = cmd.Flags().Set("output", format)
+			if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "cannot be used with --text") {
+				t.Errorf("--output %s: expected an error, got %v", format, err)
+			}
+		}
+	})
+
+	t.Run("more than one path", func(t *testing.T) {
+		if err := DestroyEmojis(newTestCommand(false), []string{"a", "b"}); err == nil {
+			t.Error("Expected error when more than one path is given")
+		}
+	})
+}
+
+func TestDestroyEmojisStdinSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		noDryRun    bool
+		quiet       bool
+		wantSummary string
+	}{
+		{"removed", "Hello 😊 World 🚀", true, false, "Summary: removed 2 emoji(s) from stdin\n"},
+		{"dry run", "Hello 😊", false, false, "Summary: would remove 1 emoji(s) from stdin (dry run)\n"},
+		{"no emojis", "Hello World", true, false, "Summary: no emojis found in stdin\n"},
+		{"quiet", "Hello 😊", true, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newTestCommand(tt.noDryRun)
+			if tt.quiet {
+				_ = cmd.Flags().Set("quiet", "true")
+			}
+
+			var stderr string
+			_ = captureStdout(t, func() {
+				withStdin(t, tt.content, func() {
+					stderr = captureStderr(t, func() {
+						if err := DestroyEmojis(cmd, []string{"-"}); err != nil {
+							t.Errorf("DestroyEmojis() error = %v", err)
+						}
+					})
+				})
+			})
+
+			if tt.wantSummary == "" {
+				if stderr != "" {
+					t.Errorf("Expected no stderr output in quiet mode, got %q", stderr)
+				}
+				return
+			}
+			if !strings.HasSuffix(stderr, tt.wantSummary) {
+				t.Errorf("stderr = %q, want it to end with %q", stderr, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestLoadReplaceMap(t *testing.T) {
+	dir, _ := os.MkdirTemp("", "test_replace_map")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	t.Run("valid map", func(t *testing.T) {
+		path := filepath.Join(dir, "map.txt")
+		_ = os.WriteFile(path, []byte("# comment\n🚀=[rocket]\n\n✅ = [done]\n"), 0600)
+
+		replacements, err := loadReplaceMap(path)
+		if err != nil {
+			t.Fatalf("loadReplaceMap() error = %v", err)
+		}
+		if replacements["🚀"] != "[rocket]" || replacements["✅"] != " [done]" || len(replacements) != 2 {
+			t.Errorf("loadReplaceMap() = %q", replacements)
+		}
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		path := filepath.Join(dir, "bad.txt")
+		_ = os.WriteFile(path, []byte("🚀 rocket\n"), 0600)
+		if _, err := loadReplaceMap(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("Expected invalid line error, got %v", err)
+		}
+	})
+
+	t.Run("applied to text", func(t *testing.T) {
+		path := filepath.Join(dir, "apply.txt")
+		_ = os.WriteFile(path, []byte("🚀=[rocket]\n✅=[done]\n"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("text", "✅ ship 🚀 party 🎉")
+		_ = cmd.Flags().Set("replace-map", path)
+		output := captureStdout(t, func() {
+			_ = DestroyEmojis(cmd, []string{})
+		})
+		if output != "[done] ship [rocket] party \n" {
+			t.Errorf("stdout = %q", output)
+		}
+	})
+}
+
+func TestDestroyEmojisReportsReplacements(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.md")
+	_ = os.WriteFile(file, []byte("✅ ship 🚀 party 🎉\n"), 0600)
+	mapPath := filepath.Join(t.TempDir(), "shortcodes.txt")
+	_ = os.WriteFile(mapPath, []byte("🚀=:rocket:\n✅=:check_mark_button:\n"), 0600)
+
+	t.Run("json", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("replace-map", mapPath)
+		_ = cmd.Flags().Set("output", "json")
+		output := captureStdout(t, func() {
+			_ = DestroyEmojis(cmd, []string{dir})
+		})
+
+		var parsed JSONOutput
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		want := map[string]string{"🚀": ":rocket:", "✅": ":check_mark_button:"}
+		if len(parsed.Files) != 1 || !reflect.DeepEqual(parsed.Files[0].Replacements, want) {
+			t.Errorf("replacements = %+v, want %q", parsed.Files, want)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("replace-map", mapPath)
+		output := captureStdout(t, func() {
+			_ = DestroyEmojis(cmd, []string{dir})
+		})
+		if want := `Replaced: ✅ → ":check_mark_button:", 🚀 → ":rocket:"`; !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	})
+}
+
+func TestReportSort(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt": "🚀🚀🚀🚀 a",  // 4 occurrences of 1 emoji, 16 bytes
+		"b.txt": "✅✅✅✅✅ b", // 5 occurrences of 1 emoji, 15 bytes
+		"c.txt": "😊 🎉",     // 2 occurrences of 2 emojis, 8 bytes
+	}
+	for name, content := range files {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	}
+
+	for key, want := range map[string][]string{
+		"path":    {"a.txt", "b.txt", "c.txt"},
+		"count":   {"b.txt", "a.txt", "c.txt"},
+		"savings": {"a.txt", "b.txt", "c.txt"},
+	} {
+		t.Run(key, func(t *testing.T) {
+			cmd := newTestCommand(false)
+			_ = cmd.Flags().Set("report-sort", key)
+			_ = cmd.Flags().Set("output", "json")
+			output := captureStdout(t, func() {
+				_ = DestroyEmojis(cmd, []string{dir})
+			})
+			var parsed JSONOutput
+			if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+				t.Fatalf("Invalid JSON output: %v", err)
+			}
+			var got []string
+			for _, file := range parsed.Files {
+				got = append(got, filepath.Base(file.FilePath))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("JSON order = %q, want %q", got, want)
+			}
+
+			cmd = newTestCommand(false)
+			_ = cmd.Flags().Set("report-sort", key)
+			output = captureStdout(t, func() {
+				_ = DestroyEmojis(cmd, []string{dir})
+			})
+			got = nil
+			for _, line := range strings.Split(output, "\n") {
+				if path, ok := strings.CutPrefix(line, "File: "); ok {
+					got = append(got, filepath.Base(path))
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Text order = %q, want %q", got, want)
+			}
+		})
+	}
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("report-sort", "size")
+	if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid report-sort") {
+		t.Errorf("Expected invalid report-sort error, got %v", err)
+	}
+}
+
+func TestDestroyEmojisCheck(t *testing.T) {
+	t.Run("clean tree", func(t *testing.T) {
+		dir := t.TempDir()
+		_ = os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("no emojis here\n"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("check", "true")
+		var err error
+		output := captureStdout(t, func() {
+			err = DestroyEmojis(cmd, []string{dir})
+		})
+		if err != nil {
+			t.Errorf("Expected no error for a clean tree, got %v", err)
+		}
+		if output != "" {
+			t.Errorf("Expected no output for a clean tree, got %q", output)
+		}
+	})
+
+	t.Run("dirty tree", func(t *testing.T) {
+		dir := t.TempDir()
+		dirty := filepath.Join(dir, "dirty.txt")
+		_ = os.WriteFile(dirty, []byte("Hello 😊\n"), 0600)
+		_ = os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("plain\n"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("check", "true")
+		var err error
+		output := captureStdout(t, func() {
+			err = DestroyEmojis(cmd, []string{dir})
+		})
+		if err == nil || !strings.Contains(err.Error(), "1 file(s) would be changed") {
+			t.Errorf("Expected would-change error, got %v", err)
+		}
+		if output != dirty+"\n" {
+			t.Errorf("Expected only the dirty file to be listed, got %q", output)
+		}
+		if content, _ := os.ReadFile(dirty); string(content) != "Hello 😊\n" {
+			t.Errorf("--check must not modify files, got %q", content)
+		}
+	})
+
+	t.Run("line ending conflict", func(t *testing.T) {
+		dir := t.TempDir()
+		dirty := filepath.Join(dir, "mac.txt")
+		_ = os.WriteFile(dirty, []byte("old mac\r🚀\n"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("check", "true")
+		var err error
+		output := captureStdout(t, func() {
+			err = DestroyEmojis(cmd, []string{dir})
+		})
+		if err == nil || !strings.Contains(err.Error(), "1 file(s) would be changed") {
+			t.Errorf("Expected would-change error, got %v", err)
+		}
+		if output != dirty+"\n" {
+			t.Errorf("Expected the file to be listed, got %q", output)
+		}
+
+		// The text report still shows the emoji and says the file is not written
+		cmd = newTestCommand(true)
+		output = captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+		if !strings.Contains(output, "Emojis found: [🚀]") || !strings.Contains(output, "Line endings: removing emojis would change them, not written") {
+			t.Errorf("Expected the emoji and the conflict in the report, got:\n%s", output)
+		}
+		if content, _ := os.ReadFile(dirty); string(content) != "old mac\r🚀\n" {
+			t.Errorf("Expected the file to be left alone, got %q", content)
+		}
+	})
+
+	t.Run("protected file", func(t *testing.T) {
+		dir := t.TempDir()
+		_ = os.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte("Released 🎉\n"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("check", "true")
+		_ = cmd.Flags().Set("protect", "CHANGELOG.md")
+		var err error
+		output := captureStdout(t, func() {
+			err = DestroyEmojis(cmd, []string{dir})
+		})
+		if err != nil {
+			t.Errorf("Expected protected files not to fail --check, got %v", err)
+		}
+		if output != "" {
+			t.Errorf("Expected no files listed, got %q", output)
+		}
+	})
+
+	t.Run("report only", func(t *testing.T) {
+		dir := t.TempDir()
+		dirty := filepath.Join(dir, "dirty.txt")
+		_ = os.WriteFile(dirty, []byte("Hello 😊\n"), 0600)
+
+		for _, output := range []string{"text", "json"} {
+			cmd := newTestCommand(false)
+			_ = cmd.Flags().Set("check", "true")
+			_ = cmd.Flags().Set("report-only", "true")
+			_ = cmd.Flags().Set("output", output)
+			var err error
+			report := captureStdout(t, func() {
+				err = DestroyEmojis(cmd, []string{dir})
+			})
+			if err != nil {
+				t.Errorf("Expected exit 0 with --report-only and %s output, got %v", output, err)
+			}
+			if !strings.Contains(report, dirty) {
+				t.Errorf("Expected the dirty file in the %s report, got %q", output, report)
+			}
+		}
+	})
+
+	t.Run("rejects no-dry-run", func(t *testing.T) {
+		cmd := newTestCommand(true)
+		_ = cmd.Flags().Set("check", "true")
+		if err := DestroyEmojis(cmd, []string{t.TempDir()}); err == nil {
+			t.Error("Expected an error combining --check with --no-dry-run")
+		}
+	})
+}
+
+func TestDestroyEmojisStaged(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tree")
+	_ = os.Mkdir(dir, 0750)
+	file := filepath.Join(dir, "a.txt")
+	_ = os.WriteFile(file, []byte("Hello 😊"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("staged", "true")
+	_ = captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if content, _ := os.ReadFile(file); string(content) != "Hello " {
+		t.Errorf("Expected staged run to clean the file, got %q", content)
+	}
+
+	cmd = newTestCommand(true)
+	_ = cmd.Flags().Set("staged", "true")
+	withStdin(t, "Hello 😊", func() {
+		if err := DestroyEmojis(cmd, []string{"-"}); err == nil {
+			t.Error("Expected an error using --staged with stdin")
+		}
+	})
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"1B", 1, false},
+		{"4KB", 4096, false},
+		{"4k", 4096, false},
+		{"1.5MiB", 1572864, false},
+		{"2 GB", 2 << 30, false},
+		{"", 0, true},
+		{"KB", 0, true},
+		{"-1", 0, true},
+		{"10TB", 0, true},
+		{"1.2.3", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := parseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if size != tt.expected {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.input, size, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFlagsFileSize(t *testing.T) {
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("min-file-size", "2KB")
+	_ = cmd.Flags().Set("max-file-size", "1KB")
+	if _, err := parseFlags(cmd); err == nil {
+		t.Error("Expected an error when the minimum exceeds the maximum")
+	}
+
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("max-file-size", "lots")
+	if _, err := parseFlags(cmd); err == nil {
+		t.Error("Expected an error for an invalid size")
+	}
+}
+
+func TestDestroyEmojisPreview(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "many.txt")
+	_ = os.WriteFile(file, []byte("intro\nab😊 🚀\n🎉 ✨ 🔥\n"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("preview", "true")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "  First emoji: 😊 at line 2, column 3 (+4 more)\n") {
+		t.Errorf("Expected truncated preview line, got:\n%s", output)
+	}
+	if strings.Contains(output, "Emojis found:") || strings.Contains(output, "🔥") {
+		t.Errorf("Expected the full emoji list to be omitted, got:\n%s", output)
+	}
+}
+
+func TestOutputJSONStats(t *testing.T) {
+	results := []emoji.ProcessResult{
+		{FilePath: "a.txt", EmojisFound: []string{"😊"}, Occurrences: 1, Modified: true},
+		{FilePath: "b.txt", EmojisFound: []string{"🚀", "✨"}, Occurrences: 4, Modified: true},
+		{FilePath: "c.txt", EmojisFound: []string{"✅", "❌"}, Occurrences: 2, Modified: true},
+		{FilePath: "zip://d.zip!e.txt", EmojisFound: []string{"👍", "👎", "🎈", "💔", "😎", "😢"}, Occurrences: 6},
+	}
+
+	output := captureStdout(t, func() {
+		_ = outputJSON(results, &commandConfig{output: "json"}, "")
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	expected := JSONStats{Files: 3, Min: 1, Max: 4, Average: 7.0 / 3.0}
+	if parsed.Summary.Stats == nil || *parsed.Summary.Stats != expected {
+		t.Errorf("stats = %+v, want %+v", parsed.Summary.Stats, expected)
+	}
+
+	output = captureStdout(t, func() {
+		_ = outputJSON(nil, &commandConfig{output: "json"}, "")
+	})
+	if strings.Contains(output, `"stats"`) {
+		t.Errorf("Expected no stats block without files, got %s", output)
+	}
+
+	t.Run("repeated emojis", func(t *testing.T) {
+		dir := t.TempDir()
+		_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("😊 and 😊 and 😊"), 0600)
+		_ = os.WriteFile(filepath.Join(dir, "b.txt"), []byte("🚀"), 0600)
+
+		cmd := newTestCommand(true)
+		_ = cmd.Flags().Set("output", "json")
+		output := captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+
+		var parsed JSONOutput
+		if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+			t.Fatalf("Invalid JSON output: %v", err)
+		}
+		expected := JSONStats{Files: 2, Min: 1, Max: 3, Average: 2}
+		if parsed.Summary.Stats == nil || *parsed.Summary.Stats != expected {
+			t.Errorf("stats = %+v, want %+v", parsed.Summary.Stats, expected)
+		}
+	})
+}
+
+func TestDestroyEmojisEmojiOnlyLines(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("keep\n🚀 🎉\ntext ✨\n"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("emoji-only-lines", "true")
+	output := captureStdout(t, func() {
+		_ = DestroyEmojis(cmd, []string{dir})
+	})
+	if !strings.Contains(output, "  Emoji-only lines: 2\n") {
+		t.Errorf("Expected emoji-only lines in the report, got:\n%s", output)
+	}
+}
+
+func TestDestroyEmojisGzipOutput(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Hello 😊"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "json")
+	_ = cmd.Flags().Set("gzip-output", "true")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	reader, err := gzip.NewReader(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Output is not gzip data: %v", err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress output: %v", err)
+	}
+
+	var parsed JSONOutput
+	if err := json.Unmarshal(decompressed, &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if parsed.Summary.TotalFiles != 1 || parsed.Files[0].EmojisFound[0] != "😊" {
+		t.Errorf("Unexpected JSON output: %+v", parsed)
+	}
+
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("gzip-output", "true")
+	if _, err := parseFlags(cmd); err == nil {
+		t.Error("Expected an error using --gzip-output without --output json")
+	}
+}
+
+func TestDestroyEmojisRepoRootGuard(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(repo, "docs")
+	if err := os.Mkdir(sub, 0750); err != nil {
+		t.Fatal(err)
+	}
+	rootFile := filepath.Join(repo, "a.txt")
+	subFile := filepath.Join(sub, "b.txt")
+	_ = os.WriteFile(rootFile, []byte("Hello 😊"), 0600)
+	_ = os.WriteFile(subFile, []byte("Hello 😊"), 0600)
+
+	cmd := newTestCommand(true)
+	var err error
+	captureStdout(t, func() { err = DestroyEmojis(cmd, []string{repo}) })
+	if err == nil || !strings.Contains(err.Error(), "git repository root") {
+		t.Fatalf("Expected the repository root guard to fire, got %v", err)
+	}
+	if content, _ := os.ReadFile(rootFile); string(content) != "Hello 😊" {
+		t.Errorf("File was modified despite the guard: %q", content)
+	}
+
+	// Dry runs and subdirectories are not guarded
+	captureStdout(t, func() { err = DestroyEmojis(newTestCommand(false), []string{repo}) })
+	if err != nil {
+		t.Errorf("Dry run on repository root error = %v", err)
+	}
+	captureStdout(t, func() { err = DestroyEmojis(newTestCommand(true), []string{sub}) })
+	if err != nil {
+		t.Errorf("Subdirectory error = %v", err)
+	}
+
+	for _, flag := range []string{"i-understand", "yes"} {
+		_ = os.WriteFile(rootFile, []byte("Hello 😊"), 0600)
+		cmd := newTestCommand(true)
+		_ = cmd.Flags().Set(flag, "true")
+		captureStdout(t, func() { err = DestroyEmojis(cmd, []string{repo}) })
+		if err != nil {
+			t.Errorf("--%s: DestroyEmojis() error = %v", flag, err)
+		}
+		if content, _ := os.ReadFile(rootFile); string(content) != "Hello " {
+			t.Errorf("--%s: file not cleaned: %q", flag, content)
+		}
+	}
+}
+
+func TestDestroyEmojisSortEmojis(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("🚀 then 😊 then ✅ then 🎉"), 0600)
+	sorted := []string{"✅", "🎉", "😊", "🚀"}
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "json")
+	_ = cmd.Flags().Set("sort-emojis", "true")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if got := strings.Join(parsed.Files[0].EmojisFound, " "); got != strings.Join(sorted, " ") {
+		t.Errorf("JSON emojis = %s, want %s", got, strings.Join(sorted, " "))
+	}
+
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("sort-emojis", "true")
+	output = captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if want := "Emojis found: [" + strings.Join(sorted, " ") + "]"; !strings.Contains(output, want) {
+		t.Errorf("Expected %q in text output, got:\n%s", want, output)
+	}
+
+	// Without the flag the list stays in discovery order
+	output = captureStdout(t, func() {
+		if err := DestroyEmojis(newTestCommand(false), []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, "Emojis found: [🚀 😊 ✅ 🎉]") {
+		t.Errorf("Expected discovery order without --sort-emojis, got:\n%s", output)
+	}
+}
+
+func TestDestroyEmojisInPlaceFrom(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "notes.md")
+	_ = os.WriteFile(file, []byte("Ship it 🚀\n"), 0600)
+
+	// Dry run reports without touching the file
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("in-place-from", file)
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, nil); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, "Would remove 1 emoji(s)") {
+		t.Errorf("Expected dry-run report, got:\n%s", output)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "Ship it 🚀\n" {
+		t.Errorf("Dry run modified the file: %q", content)
+	}
+
+	cmd = newTestCommand(true)
+	_ = cmd.Flags().Set("in-place-from", file)
+	captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, nil); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if content, _ := os.ReadFile(file); string(content) != "Ship it \n" {
+		t.Errorf("File not cleaned in place: %q", content)
+	}
+
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("in-place-from", file)
+	_ = cmd.Flags().Set("text", "Hi 😊")
+	if _, err := parseFlags(cmd); err == nil {
+		t.Error("Expected an error combining --in-place-from with --text")
+	}
+
+	t.Run("line ending conflict", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "mac.txt")
+		_ = os.WriteFile(file, []byte("old mac\r🚀\n"), 0600)
+
+		cmd := newTestCommand(true)
+		_ = cmd.Flags().Set("in-place-from", file)
+		output := captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, nil); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+		if strings.Contains(output, "No emojis found") || !strings.Contains(output, "Line endings: removing emojis would change them, not written") {
+			t.Errorf("Expected the emoji and the conflict in the report, got:\n%s", output)
+		}
+		if content, _ := os.ReadFile(file); string(content) != "old mac\r🚀\n" {
+			t.Errorf("Expected the file to be left alone, got %q", content)
+		}
+	})
+}
+
+func TestDestroyEmojisJSONIndent(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Hello 😊"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "json")
+	_ = cmd.Flags().Set("json-indent", "tab")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if !strings.HasPrefix(output, "{\n\t\"summary\": {\n\t\t\"total_files\": 1") {
+		t.Errorf("Expected tab-indented JSON, got:\n%s", output)
+	}
+
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("output", "json")
+	output = captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if !strings.HasPrefix(output, "{\n  \"summary\": {\n    \"total_files\": 1") {
+		t.Errorf("Expected two-space indented JSON by default, got:\n%s", output)
+	}
+
+	for _, value := range []string{"-1", "17", "tabs"} {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("json-indent", value)
+		if _, err := parseFlags(cmd); err == nil {
+			t.Errorf("Expected an error for --json-indent %s", value)
+		}
+	}
+}
+
+func TestDestroyEmojisInlineAllow(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	_ = os.WriteFile(file, []byte("Hello 😊 World 🚀 Done ✅"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("allow", "😊")
+	_ = cmd.Flags().Set("allow", "U+2705")
+	captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if content, _ := os.ReadFile(file); string(content) != "Hello 😊 World  Done ✅" {
+		t.Errorf("Expected allowed emojis to survive, got %q", content)
+	}
+
+	// Stdin content honors --allow too
+	cmd = newTestCommand(true)
+	_ = cmd.Flags().Set("allow", "😊")
+	_ = cmd.Flags().Set("quiet", "true")
+	withStdin(t, "Hi 😊 and 🎉", func() {
+		output := captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{"-"}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+		if output != "Hi 😊 and " {
+			t.Errorf("Stdin output = %q", output)
+		}
+	})
+}
+
+func TestParseFlagsInlineAllowMergesAllowFile(t *testing.T) {
+	dir := t.TempDir()
+	allowPath := writeAllowFile(t, dir, "✅")
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("allow-file", allowPath)
+	_ = cmd.Flags().Set("allow", "😊")
+	config, err := parseFlags(cmd)
+	if err != nil {
+		t.Fatalf("parseFlags() error = %v", err)
+	}
+	if got := strings.Join(config.allowedEmojis, " "); got != "✅ 😊" {
+		t.Errorf("allowedEmojis = %q, want allow file entries followed by --allow", got)
+	}
+
+	for _, value := range []string{"U+ZZZZ", "U+D800", " "} {
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("allow", value)
+		if _, err := parseFlags(cmd); err == nil {
+			t.Errorf("Expected an error for --allow %q", value)
+		}
+	}
+}
+
+func TestDestroyEmojisNever(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "engine.md")
+	_ = os.WriteFile(file, []byte("⚙️ Settings 😊\n⚙ Tuning\n"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("never", "⚙️")
+	captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{file}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if content, _ := os.ReadFile(file); string(content) != "⚙️ Settings \n⚙ Tuning\n" {
+		t.Errorf("Expected only 😊 removed, got %q", content)
+	}
+
+	for value, want := range map[string]rune{"⚙": '⚙', "u+2699": '⚙', " U+1F60A ": '😊'} {
+		if got, err := parseNeverValue(value); err != nil || got != want {
+			t.Errorf("parseNeverValue(%q) = %U, %v, want %U", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "U+ZZZZ", "U+D800", "⚙😊", "ab"} {
+		if _, err := parseNeverValue(value); err == nil {
+			t.Errorf("Expected an error for --never %q", value)
+		}
+	}
+}
+
+func TestDestroyEmojisTargetFromEnv(t *testing.T) {
+	envDir := t.TempDir()
+	argDir := t.TempDir()
+	envFile := filepath.Join(envDir, "env.txt")
+	argFile := filepath.Join(argDir, "arg.txt")
+	_ = os.WriteFile(envFile, []byte("From env 😊"), 0600)
+	_ = os.WriteFile(argFile, []byte("From arg 🚀"), 0600)
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{"env only", envDir, nil, envFile},
+		{"arg only", "", []string{argDir}, argFile},
+		{"arg overrides env", envDir, []string{argDir}, argFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EMOJI_SAD_TARGET", tt.env)
+			cmd := newTestCommand(false)
+			_ = cmd.Flags().Set("output", "json")
+			output := captureStdout(t, func() {
+				if err := DestroyEmojis(cmd, tt.args); err != nil {
+					t.Errorf("DestroyEmojis() error = %v", err)
+				}
+			})
+
+			var parsed JSONOutput
+			if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+				t.Fatalf("Invalid JSON output: %v", err)
+			}
+			if len(parsed.Files) != 1 || parsed.Files[0].FilePath != tt.want {
+				t.Errorf("Expected only %s in the report, got %+v", tt.want, parsed.Files)
+			}
+		})
+	}
+
+	t.Run("defaults to the current directory", func(t *testing.T) {
+		t.Setenv("EMOJI_SAD_TARGET", "")
+		oldWd, _ := os.Getwd()
+		if err := os.Chdir(argDir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(oldWd) }()
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("list-only", "true")
+		output := captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, nil); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+		if strings.TrimSpace(output) != "arg.txt" {
+			t.Errorf("Expected arg.txt to be listed, got %q", output)
+		}
+	})
+}
+
+func TestDestroyEmojisMinDensity(t *testing.T) {
+	dir := t.TempDir()
+	dense := filepath.Join(dir, "dense.txt")
+	sparse := filepath.Join(dir, "sparse.txt")
+	_ = os.WriteFile(dense, []byte("🎉🎉🚀 ok 🔥"), 0600)
+	_ = os.WriteFile(sparse, []byte("A long sentence that happens to end with one emoji 😊"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "json")
+	_ = cmd.Flags().Set("min-density", "50")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if parsed.Summary.TotalFiles != 2 || parsed.Summary.ShownFiles == nil || *parsed.Summary.ShownFiles != 1 || parsed.Summary.MinDensity != 50 {
+		t.Errorf("Expected 1 of 2 files shown at a density above 50%%, got %+v", parsed.Summary)
+	}
+	if len(parsed.Files) != 1 || parsed.Files[0].FilePath != dense || parsed.Files[0].Density != 4.0/6*100 {
+		t.Errorf("Expected only dense.txt with a density of 66.7%%, got %+v", parsed.Files)
+	}
+
+	// Text output notes the full run, and every file was still cleaned
+	cmd = newTestCommand(true)
+	_ = cmd.Flags().Set("min-density", "50")
+	output = captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, "dense.txt") || strings.Contains(output, "sparse.txt") {
+		t.Errorf("Expected only dense.txt in the report, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Showing 1 of 2 file(s) with an emoji density above 50%; the full run found 4 emoji(s) in 2 file(s).") {
+		t.Errorf("Expected a note about the full run, got:\n%s", output)
+	}
+	for _, file := range []string{dense, sparse} {
+		if content, _ := os.ReadFile(file); strings.ContainsAny(string(content), "🎉🚀🔥😊") {
+			t.Errorf("%s was not cleaned: %q", file, content)
+		}
+	}
+
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("min-density", "100")
+	if _, err := parseFlags(cmd); err == nil || !strings.Contains(err.Error(), "invalid min-density") {
+		t.Errorf("Expected invalid min-density error, got %v", err)
+	}
+}
+
+func TestDestroyEmojisMinEmojis(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "one.txt"), []byte("Hi 😊"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "three.txt"), []byte("🚀 🚀 🚀"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "four.txt"), []byte("😊 🎉 ✅ 🚀"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "json")
+	_ = cmd.Flags().Set("min-emojis", "3")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if parsed.Summary.TotalFiles != 3 || parsed.Summary.TotalEmojis != 6 {
+		t.Errorf("Summary should cover the full run, got %+v", parsed.Summary)
+	}
+	if parsed.Summary.ShownFiles == nil || *parsed.Summary.ShownFiles != 2 || parsed.Summary.MinEmojis != 3 {
+		t.Errorf("Expected 2 shown files at min 3, got %+v", parsed.Summary)
+	}
+	var occurrences []int
+	for _, file := range parsed.Files {
+		occurrences = append(occurrences, file.Occurrences)
+	}
+	if !reflect.DeepEqual(occurrences, []int{4, 3}) {
+		t.Errorf("Expected four.txt and three.txt with 4 and 3 occurrences, got %v", occurrences)
+	}
+
+	// Text output lists only the matching files and notes the full run
+	cmd = newTestCommand(true)
+	_ = cmd.Flags().Set("min-emojis", "4")
+	output = captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if !strings.Contains(output, "four.txt") || strings.Contains(output, "one.txt") || strings.Contains(output, "three.txt") {
+		t.Errorf("Expected only four.txt in the report, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Showing 1 of 3 file(s) with at least 4 emoji occurrence(s); the full run found 6 emoji(s) in 3 file(s).") {
+		t.Errorf("Expected a note about the full run, got:\n%s", output)
+	}
+
+	// Every file was still cleaned
+	for _, name := range []string{"one.txt", "three.txt", "four.txt"} {
+		content, _ := os.ReadFile(filepath.Join(dir, name))
+		if strings.ContainsAny(string(content), "😊🚀🎉✅") {
+			t.Errorf("%s was not cleaned: %q", name, content)
+		}
+	}
+}
+
+func TestDestroyEmojisProtect(t *testing.T) {
+	dir := t.TempDir()
+	protected := filepath.Join(dir, "CHANGELOG.md")
+	_ = os.WriteFile(protected, []byte("Released 🎉"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Hi 😊"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("output", "json")
+	_ = cmd.Flags().Set("protect", "CHANGELOG.md")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var parsed JSONOutput
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	for _, file := range parsed.Files {
+		if wantProtected := file.FilePath == protected; file.Protected != wantProtected {
+			t.Errorf("%s: protected = %v, want %v", file.FilePath, file.Protected, wantProtected)
+		}
+	}
+	if !strings.Contains(output, `"protected": true`) {
+		t.Errorf("Expected a protected entry in the JSON output, got:\n%s", output)
+	}
+
+	if content, _ := os.ReadFile(protected); string(content) != "Released 🎉" {
+		t.Errorf("Protected file was written: %q", content)
+	}
+}
+
+func TestDestroyEmojisWarnOnEmpty(t *testing.T) {
+	dir := t.TempDir()
+	allEmoji := filepath.Join(dir, "reactions.txt")
+	_ = os.WriteFile(allEmoji, []byte("🎉 🚀\n😊\n"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Hi 😊"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("warn-on-empty", "true")
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+	})
+
+	if !strings.Contains(stderr, "cleaned content is empty") || !strings.Contains(stderr, allEmoji) {
+		t.Errorf("Expected a warning naming %s, got stderr:\n%s", allEmoji, stderr)
+	}
+	if strings.Contains(stderr, "notes.md") {
+		t.Errorf("Did not expect a warning for notes.md, got stderr:\n%s", stderr)
+	}
+	if !strings.Contains(output, "Emptied: cleaned content is blank") {
+		t.Errorf("Expected the emptied file to be flagged, got:\n%s", output)
+	}
+}
+
+func TestDestroyEmojisRefuseEmpty(t *testing.T) {
+	dir := t.TempDir()
+	allEmoji := filepath.Join(dir, "reactions.txt")
+	notes := filepath.Join(dir, "notes.md")
+	_ = os.WriteFile(allEmoji, []byte("🎉 🚀"), 0600)
+	_ = os.WriteFile(notes, []byte("Hi 😊"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("refuse-empty", "true")
+	_ = cmd.Flags().Set("output", "json")
+	var output string
+	captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+	})
+
+	if !strings.Contains(output, `"write_refused": true`) {
+		t.Errorf("Expected a write_refused entry in the JSON output, got:\n%s", output)
+	}
+	if content, _ := os.ReadFile(allEmoji); string(content) != "🎉 🚀" {
+		t.Errorf("File that would be emptied was written: %q", content)
+	}
+	if content, _ := os.ReadFile(notes); string(content) != "Hi " {
+		t.Errorf("notes.md = %q, want %q", content, "Hi ")
+	}
+}
+
+func TestDestroyEmojisTrace(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Launch 🚀"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("trace", "true")
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+				t.Errorf("DestroyEmojis() error = %v", err)
+			}
+		})
+	})
+
+	if !strings.Contains(stderr, "U+1F680") {
+		t.Errorf("Expected the trace to mention U+1F680, got stderr:\n%s", stderr)
+	}
+	if strings.Contains(output, "trace:") {
+		t.Errorf("Trace leaked into stdout:\n%s", output)
+	}
+}
+
+func TestDestroyEmojisListOnlyPrint0(t *testing.T) {
+	dir := t.TempDir()
+	withNewline := filepath.Join(dir, "odd\nname.md")
+	plain := filepath.Join(dir, "notes.md")
+	_ = os.WriteFile(withNewline, []byte("Hi 😊"), 0600)
+	_ = os.WriteFile(plain, []byte("Hi 🚀"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("list-only", "true")
+	_ = cmd.Flags().Set("print0", "true")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	paths := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+	sort.Strings(paths)
+	if want := []string{plain, withNewline}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("NUL-separated paths = %q, want %q", paths, want)
+	}
+	if !strings.HasSuffix(output, "\x00") {
+		t.Errorf("Expected each path to end with a NUL byte, got %q", output)
+	}
+}
+
+func TestDestroyEmojisPrint0RequiresList(t *testing.T) {
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("print0", "true")
+	if err := DestroyEmojis(cmd, []string{t.TempDir()}); err == nil || !strings.Contains(err.Error(), "--print0 requires") {
+		t.Errorf("DestroyEmojis() error = %v, want --print0 requires error", err)
+	}
+}
+
+func TestDestroyEmojisMarkdownAware(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "guide.md"), []byte("Launch 🚀\n\n```\nok ✅\n```\n"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("markdown-aware", "true")
+	_ = cmd.Flags().Set("output", "json")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var report JSONOutput
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(report.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %+v", report.Files)
+	}
+	file := report.Files[0]
+	if !reflect.DeepEqual(file.EmojisFound, []string{"🚀"}) || !reflect.DeepEqual(file.Preserved, []string{"✅"}) {
+		t.Errorf("Expected 🚀 found and ✅ preserved, got %+v", file)
+	}
+}
+
+func TestDestroyEmojisRelative(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "docs"), 0750)
+	_ = os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("Read me ✨"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("Hi 😊"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("relative", "true")
+	_ = cmd.Flags().Set("list-only", "true")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	if want := filepath.Join("docs", "guide.md") + "\nnotes.md\n"; output != want {
+		t.Errorf("Output = %q, want %q", output, want)
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		root, path, expected string
+	}{
+		{"/abs/project", "/abs/project/docs/a.md", "docs/a.md"},
+		{"project", "project/a.md", "a.md"},
+		{"/abs/project", "zip:///abs/project/bundle.zip!inner/a.md", "zip://bundle.zip!inner/a.md"},
+		{"/abs/project/a.md", "/abs/project/a.md", "a.md"},
+		{"/abs/project", "relative/a.md", "relative/a.md"},
+	}
+	for _, tt := range tests {
+		if got := relativePath(tt.root, tt.path); got != tt.expected {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tt.root, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestDestroyEmojisCheckpointResume(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "tree")
+	_ = os.Mkdir(dir, 0750)
+	done := filepath.Join(dir, "done.txt")
+	remaining := filepath.Join(dir, "remaining.txt")
+	_ = os.WriteFile(done, []byte("Hello 😊"), 0600)
+	_ = os.WriteFile(remaining, []byte("Hello 😊"), 0600)
+
+	// A checkpoint left behind by an interrupted run that got through done.txt
+	checkpointPath := filepath.Join(tempDir, "scan.checkpoint")
+	_ = os.WriteFile(checkpointPath, []byte(done+"\n"), 0600)
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("checkpoint", checkpointPath)
+	captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	if content, _ := os.ReadFile(done); string(content) != "Hello 😊" {
+		t.Errorf("File recorded in the checkpoint was processed again: %q", content)
+	}
+	if content, _ := os.ReadFile(remaining); string(content) != "Hello " {
+		t.Errorf("Remaining file was not cleaned: %q", content)
+	}
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed on completion, stat error = %v", err)
+	}
+
+	cmd = newTestCommand(true)
+	_ = cmd.Flags().Set("checkpoint", checkpointPath)
+	_ = cmd.Flags().Set("staged", "true")
+	if _, err := parseFlags(cmd); err == nil {
+		t.Error("Expected an error combining --checkpoint with --staged")
+	}
+}
+
+func TestDestroyEmojisGitTrackedOnly(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	tracked := filepath.Join(dir, "tracked.txt")
+	untracked := filepath.Join(dir, "untracked.txt")
+	_ = os.WriteFile(tracked, []byte("Hello 😊"), 0600)
+	_ = os.WriteFile(untracked, []byte("Hello 😊"), 0600)
+	for _, args := range [][]string{{"init", "-q"}, {"add", "tracked.txt"}} {
+		if output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("git-tracked-only", "true")
+	_ = cmd.Flags().Set("yes", "true") // The target is a repository root
+	captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	if content, _ := os.ReadFile(tracked); string(content) != "Hello " {
+		t.Errorf("Tracked file was not cleaned: %q", content)
+	}
+	if content, _ := os.ReadFile(untracked); string(content) != "Hello 😊" {
+		t.Errorf("Untracked file was processed: %q", content)
+	}
+
+	outside := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(outside))
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("git-tracked-only", "true")
+	if err := DestroyEmojis(cmd, []string{outside}); err == nil {
+		t.Error("Expected an error outside a git repository")
+	}
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+// htmlReportTemplate renders a self-contained report page. html/template escapes
+// every path and emoji, so file names cannot inject markup.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Emoji Search and Destroy Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Emoji Search and Destroy Report</h1>
+<p class="summary">{{if .DryRun}}Dry run: would remove{{else}}Removed{{end}} {{.TotalEmojis}} emoji(s) from {{.TotalFiles}} file(s)</p>
+{{- if .Files}}
+<table>
+<thead><tr><th>File</th><th>Emojis</th><th>Count</th></tr></thead>
+<tbody>
+{{- range .Files}}
+<tr><td>{{.Path}}</td><td>{{.Emojis}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>No emojis found in any files.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlReport is the data rendered by htmlReportTemplate
+type htmlReport struct {
+	DryRun      bool
+	TotalFiles  int
+	TotalEmojis int
+	Files       []htmlFileRow
+}
+
+// htmlFileRow is a single file row in the HTML report
+type htmlFileRow struct {
+	Path   string
+	Emojis string
+	Count  int
+}
+
+// outputHTML writes the results as a self-contained HTML page with the summary at the top
+func outputHTML(out io.Writer, results []emoji.ProcessResult, dryRun bool) error {
+	report := htmlReport{
+		DryRun:     dryRun,
+		TotalFiles: len(results),
+		Files:      make([]htmlFileRow, 0, len(results)),
+	}
+	for _, result := range results {
+		report.TotalEmojis += len(result.EmojisFound)
+		report.Files = append(report.Files, htmlFileRow{
+			Path:   result.FilePath,
+			Emojis: strings.Join(result.EmojisFound, " "),
+			Count:  len(result.EmojisFound),
+		})
+	}
+
+	if err := htmlReportTemplate.Execute(out, report); err != nil {
+		return fmt.Errorf("failed to render HTML output: %w", err)
+	}
+	return nil
+}
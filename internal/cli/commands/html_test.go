@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+func TestOutputHTML(t *testing.T) {
+	results := []emoji.ProcessResult{
+		{FilePath: "docs/README.md", EmojisFound: []string{"🚀", "✨"}, OriginalSize: 20, NewSize: 13, Modified: true},
+		{FilePath: "src/<script>alert(1)</script>.txt", EmojisFound: []string{"😊"}, OriginalSize: 10, NewSize: 6, Modified: true},
+	}
+
+	var out bytes.Buffer
+	if err := outputHTML(&out, results, true); err != nil {
+		t.Fatalf("outputHTML() error = %v", err)
+	}
+	page := out.String()
+
+	expected := []string{
+		"<!DOCTYPE html>",
+		"Dry run: would remove 3 emoji(s) from 2 file(s)",
+		"<tr><td>docs/README.md</td><td>🚀 ✨</td><td>2</td></tr>",
+		"<tr><td>src/&lt;script&gt;alert(1)&lt;/script&gt;.txt</td><td>😊</td><td>1</td></tr>",
+	}
+	for _, want := range expected {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML output missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Errorf("HTML output contains an unescaped path:\n%s", page)
+	}
+	if strings.Index(page, "Dry run:") > strings.Index(page, "<table>") {
+		t.Error("Expected the summary before the file table")
+	}
+}
+
+func TestOutputHTMLNoResults(t *testing.T) {
+	var out bytes.Buffer
+	if err := outputHTML(&out, nil, false); err != nil {
+		t.Fatalf("outputHTML() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No emojis found in any files.") || strings.Contains(out.String(), "<table>") {
+		t.Errorf("Unexpected HTML for no results:\n%s", out.String())
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+// outputFormatJUnit is the --output value for JUnit XML reports
+const outputFormatJUnit = "junit"
+
+// junitTestSuite is the root element of a JUnit XML report
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single file in a JUnit XML report
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure marks a file that contains emojis
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// outputJUnit writes the results as a JUnit XML test suite for CI dashboards. Each
+// file with emojis is a failing test case and each other scanned file a passing one.
+func outputJUnit(out io.Writer, results []emoji.ProcessResult, scanned []string) error {
+	suite := junitTestSuite{Name: "emoji-sad"}
+
+	failing := make(map[string]bool, len(results))
+	for _, result := range results {
+		failing[result.FilePath] = true
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      result.FilePath,
+			ClassName: suite.Name,
+			Failure: &junitFailure{
+				Message: "emojis found: " + strings.Join(result.EmojisFound, " "),
+				Type:    "emoji",
+				Text:    fmt.Sprintf("%d emoji(s) found in %s", len(result.EmojisFound), result.FilePath),
+			},
+		})
+	}
+	for _, path := range scanned {
+		if !failing[path] {
+			suite.TestCases = append(suite.TestCases, junitTestCase{Name: path, ClassName: suite.Name})
+		}
+	}
+
+	sort.Slice(suite.TestCases, func(i, j int) bool {
+		return suite.TestCases[i].Name < suite.TestCases[j].Name
+	})
+	suite.Tests = len(suite.TestCases)
+	suite.Failures = len(results)
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit output: %w", err)
+	}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
+
+// scannedFiles collects the paths a processor reports through OnFileProcessed, so
+// files without emojis can be listed as passing test cases.
+type scannedFiles struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+// trackScanned starts recording processed paths, chaining any existing hook
+func trackScanned(processor *emoji.FileProcessor) *scannedFiles {
+	scanned := &scannedFiles{}
+	next := processor.OnFileProcessed
+	processor.OnFileProcessed = func(path string) {
+		scanned.mu.Lock()
+		scanned.paths = append(scanned.paths, path)
+		scanned.mu.Unlock()
+		if next != nil {
+			next(path)
+		}
+	}
+	return scanned
+}
+
+// list returns the recorded paths
+func (s *scannedFiles) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.paths...)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+func TestOutputJUnit(t *testing.T) {
+	results := []emoji.ProcessResult{
+		{FilePath: "b.md", EmojisFound: []string{"🚀", "✨"}, Modified: true},
+		{FilePath: "a<&>.txt", EmojisFound: []string{"😊"}, Modified: true},
+	}
+
+	var out bytes.Buffer
+	if err := outputJUnit(&out, results, []string{"a<&>.txt", "b.md", "clean.go"}); err != nil {
+		t.Fatalf("outputJUnit() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Errorf("Expected an XML header, got:\n%s", out.String())
+	}
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal(out.Bytes(), &suite); err != nil {
+		t.Fatalf("Invalid XML output: %v\n%s", err, out.String())
+	}
+	if suite.Tests != 3 || suite.Failures != 2 || len(suite.TestCases) != 3 {
+		t.Fatalf("Expected 3 tests with 2 failures, got %+v", suite)
+	}
+
+	want := []struct {
+		name    string
+		message string
+	}{
+		{"a<&>.txt", "emojis found: 😊"},
+		{"b.md", "emojis found: 🚀 ✨"},
+		{"clean.go", ""},
+	}
+	for i, tc := range suite.TestCases {
+		if tc.Name != want[i].name {
+			t.Errorf("Test case %d name = %q, want %q", i, tc.Name, want[i].name)
+		}
+		if want[i].message == "" {
+			if tc.Failure != nil {
+				t.Errorf("%s: expected a passing test case, got %+v", tc.Name, tc.Failure)
+			}
+		} else if tc.Failure == nil || tc.Failure.Message != want[i].message {
+			t.Errorf("%s: failure = %+v, want message %q", tc.Name, tc.Failure, want[i].message)
+		}
+	}
+}
+
+func TestDestroyEmojisJUnitOutput(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "one.txt"), []byte("Hi 😊"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "two.txt"), []byte("Go 🚀"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("Nothing here"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "junit")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal([]byte(output), &suite); err != nil {
+		t.Fatalf("Invalid XML output: %v\n%s", err, output)
+	}
+	failures := 0
+	for _, tc := range suite.TestCases {
+		if tc.Failure != nil {
+			failures++
+		}
+	}
+	if suite.Failures != 2 || failures != 2 || suite.Tests != 3 {
+		t.Errorf("Expected 2 failing of 3 test cases, got failures=%d (%d counted), tests=%d", suite.Failures, failures, suite.Tests)
+	}
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log formats accepted by --log-format
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// newLogger builds the logger for the tool's own operation (lifecycle events and
+// per-file warnings), kept separate from the result report. Records below level are
+// dropped.
+func newLogger(out io.Writer, format string, level string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level: %s (must be 'debug', 'info', 'warn' or 'error')", level)
+	}
+
+	options := &slog.HandlerOptions{Level: minLevel}
+	switch strings.ToLower(format) {
+	case logFormatText:
+		return slog.New(slog.NewTextHandler(out, options)), nil
+	case logFormatJSON:
+		return slog.New(slog.NewJSONHandler(out, options)), nil
+	}
+	return nil, fmt.Errorf("invalid log format: %s (must be '%s' or '%s')", format, logFormatText, logFormatJSON)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "json", "warn")
+	if err != nil {
+		t.Fatalf("newLogger() error = %v", err)
+	}
+	logger.Info("dropped below the level")
+	logger.Warn("kept", "path", "a.txt")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a single JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "kept" || record["path"] != "a.txt" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+
+	if _, err := newLogger(&buf, "xml", "warn"); err == nil {
+		t.Error("Expected an error for an invalid log format")
+	}
+	if _, err := newLogger(&buf, "text", "loud"); err == nil {
+		t.Error("Expected an error for an invalid log level")
+	}
+}
+
+func TestDestroyEmojisLogsWarningAsJSON(t *testing.T) {
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("files-from-stdin", "true")
+	_ = cmd.Flags().Set("log-format", "json")
+
+	var stderr string
+	withStdin(t, "/non/existent/file.txt\n", func() {
+		stderr = captureStderr(t, func() {
+			captureStdout(t, func() {
+				if err := DestroyEmojis(cmd, []string{"-"}); err != nil {
+					t.Errorf("DestroyEmojis() error = %v", err)
+				}
+			})
+		})
+	})
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stderr)), &record); err != nil {
+		t.Fatalf("Expected a slog JSON record on stderr, got %q: %v", stderr, err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "file does not exist" || record["path"] != "/non/existent/file.txt" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+
+	t.Run("skipped file", func(t *testing.T) {
+		dir := t.TempDir()
+		large := filepath.Join(dir, "large.txt")
+		_ = os.WriteFile(large, []byte("Hello 😊"), 0600)
+
+		cmd := newTestCommand(false)
+		_ = cmd.Flags().Set("max-file-size", "1")
+		_ = cmd.Flags().Set("log-format", "json")
+		stderr := captureStderr(t, func() {
+			captureStdout(t, func() {
+				if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+					t.Errorf("DestroyEmojis() error = %v", err)
+				}
+			})
+		})
+
+		var record map[string]any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(stderr)), &record); err != nil {
+			t.Fatalf("Expected a slog JSON record on stderr, got %q: %v", stderr, err)
+		}
+		if record["level"] != "WARN" || record["msg"] != "skipped file" || record["path"] != large || record["reason"] == nil {
+			t.Errorf("Unexpected record: %v", record)
+		}
+	})
+}
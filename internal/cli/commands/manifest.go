@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+// manifestDirectives are the per-file overrides on a manifest line
+type manifestDirectives struct {
+	allowed      []string
+	hasAllowed   bool
+	replacements map[string]string
+}
+
+// parseManifestLine splits a manifest line of the form "path<TAB>directives". Directives
+// are space-separated key=value pairs:
+//
+//	allow=✅,🚀          only these emojis are kept in this file (empty keeps none)
+//	replace=🚀:[rocket]  comma-separated emoji:replacement pairs for this file
+//
+// A line without a tab has no directives and uses the global settings.
+func parseManifestLine(line string) (string, *manifestDirectives, error) {
+	path, rest, found := strings.Cut(line, "\t")
+	path = strings.TrimSpace(path)
+	if !found || strings.TrimSpace(rest) == "" {
+		return path, nil, nil
+	}
+
+	directives := &manifestDirectives{}
+	for _, field := range strings.Fields(rest) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("%s: invalid directive %q (expected key=value)", path, field)
+		}
+
+		switch key {
+		case "allow":
+			directives.hasAllowed = true
+			directives.allowed = splitList(value)
+		case "replace":
+			directives.replacements = make(map[string]string)
+			for _, pair := range splitList(value) {
+				from, to, ok := strings.Cut(pair, ":")
+				if !ok || from == "" {
+					return "", nil, fmt.Errorf("%s: invalid replacement %q (expected emoji:text)", path, pair)
+				}
+				directives.replacements[from] = to
+			}
+		default:
+			return "", nil, fmt.Errorf("%s: unknown directive %q", path, key)
+		}
+	}
+
+	return path, directives, nil
+}
+
+// splitList splits a comma-separated directive value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// detector returns the Detector to use for a manifest line: the global one when there
+// are no directives, otherwise a copy with the overrides applied
+func (m *manifestDirectives) detector(global *emoji.Detector) *emoji.Detector {
+	if m == nil {
+		return global
+	}
+
+	detector := global.Clone()
+	if m.hasAllowed {
+		detector.WithAllowed(m.allowed)
+	}
+	if m.replacements != nil {
+		detector.WithReplacements(m.replacements)
+	}
+	return detector
+}
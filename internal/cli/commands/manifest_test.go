@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseManifestLine(t *testing.T) {
+	path, directives, err := parseManifestLine("docs/a.md")
+	if err != nil || path != "docs/a.md" || directives != nil {
+		t.Errorf("plain path = %q, %v, %v", path, directives, err)
+	}
+
+	path, directives, err = parseManifestLine("docs/b.md\tallow=✅,🚀 replace=🎉:[party]")
+	if err != nil {
+		t.Fatalf("parseManifestLine() error = %v", err)
+	}
+	if path != "docs/b.md" || !directives.hasAllowed || len(directives.allowed) != 2 || directives.replacements["🎉"] != "[party]" {
+		t.Errorf("directives = %q, %+v", path, directives)
+	}
+
+	for _, line := range []string{"a.md\tallow", "a.md\tcolor=red", "a.md\treplace=🎉"} {
+		if _, _, err := parseManifestLine(line); err == nil {
+			t.Errorf("parseManifestLine(%q) expected an error", line)
+		}
+	}
+}
+
+func TestDestroyEmojisManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"plain.txt":    "✅ 🚀 🎉",
+		"allowed.txt":  "✅ 🚀 🎉",
+		"replaced.txt": "✅ 🚀 🎉",
+	}
+	for name, content := range files {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	}
+
+	manifest := strings.Join([]string{
+		filepath.Join(dir, "plain.txt"),
+		filepath.Join(dir, "allowed.txt") + "\tallow=🚀",
+		filepath.Join(dir, "replaced.txt") + "\treplace=🎉:[party]",
+		filepath.Join(dir, "missing.txt") + "\tbogus=1",
+	}, "\n")
+
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("manifest", "true")
+	_ = cmd.Flags().Set("allow-file", writeAllowFile(t, dir, "✅"))
+	var stderr string
+	withStdin(t, manifest, func() {
+		stderr = captureStderr(t, func() {
+			_ = captureStdout(t, func() {
+				if err := DestroyEmojis(cmd, []string{"-"}); err != nil {
+					t.Errorf("DestroyEmojis() error = %v", err)
+				}
+			})
+		})
+	})
+
+	expected := map[string]string{
+		"plain.txt":    "✅  ",        // Global allow list keeps ✅
+		"allowed.txt":  " 🚀 ",        // Override keeps only 🚀
+		"replaced.txt": "✅  [party]", // Global allow list plus a per-file replacement
+	}
+	for name, want := range expected {
+		content, _ := os.ReadFile(filepath.Join(dir, name))
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+	if !strings.Contains(stderr, "unknown directive") {
+		t.Errorf("Expected a warning for the invalid manifest line, got %q", stderr)
+	}
+}
+
+// writeAllowFile writes an allow file with the given emojis and returns its path.
+func writeAllowFile(t *testing.T, dir string, emojis ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, "allow.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(emojis, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+const (
+	// progressDelay is how long a scan runs before progress is shown, so small trees stay quiet.
+	progressDelay = time.Second
+	// progressInterval is how often the progress line is refreshed.
+	progressInterval = 250 * time.Millisecond
+)
+
+// startProgress shows a live progress line on stderr while processor walks a directory.
+// Nothing is shown in quiet mode or when stderr is not an interactive terminal. The
+// returned function stops the reporter and clears the line.
+func startProgress(processor *emoji.FileProcessor, config *commandConfig) func() {
+	if config.quiet || !isTerminal(os.Stderr) {
+		return func() {}
+	}
+
+	reporter := &progressReporter{out: os.Stderr, delay: progressDelay, interval: progressInterval}
+	processor.OnFileProcessed = reporter.fileProcessed
+	return reporter.start()
+}
+
+// isTerminal reports whether f is connected to a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressReporter periodically writes the number of processed files and the current rate.
+// The walk increments a shared counter and a separate goroutine reads it on each tick.
+type progressReporter struct {
+	out       io.Writer
+	delay     time.Duration
+	interval  time.Duration
+	processed atomic.Int64
+}
+
+// fileProcessed counts one processed file. It is safe to call while the reporter runs.
+func (p *progressReporter) fileProcessed(string) {
+	p.processed.Add(1)
+}
+
+// start runs the reporter until the returned stop function is called.
+func (p *progressReporter) start() func() {
+	began := time.Now()
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		shown := false
+		for {
+			select {
+			case <-done:
+				if shown {
+					_, _ = fmt.Fprint(p.out, "\r\033[K") // Clear the progress line before the report
+				}
+				return
+			case now := <-ticker.C:
+				elapsed := now.Sub(began)
+				if elapsed < p.delay {
+					continue
+				}
+				count := p.processed.Load()
+				rate := float64(count) / elapsed.Seconds()
+				_, _ = fmt.Fprintf(p.out, "\r\033[KScanned %d file(s) (%.0f files/s)", count, rate)
+				shown = true
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
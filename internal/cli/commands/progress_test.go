@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &progressReporter{out: &out, interval: time.Millisecond}
+	stop := reporter.start()
+	for i := 0; i < 3; i++ {
+		reporter.fileProcessed("file.txt")
+	}
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	if !strings.Contains(out.String(), "Scanned 3 file(s)") {
+		t.Errorf("Expected progress line for 3 files, got %q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Errorf("Expected the progress line to be cleared on stop, got %q", out.String())
+	}
+}
+
+func TestProgressReporterDelay(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &progressReporter{out: &out, delay: time.Hour, interval: time.Millisecond}
+	stop := reporter.start()
+	reporter.fileProcessed("file.txt")
+	time.Sleep(10 * time.Millisecond)
+	stop()
+
+	if out.Len() != 0 {
+		t.Errorf("Expected no progress before the delay elapses, got %q", out.String())
+	}
+}
+
+func TestDestroyEmojisNoProgressWithoutTerminal(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		_ = os.WriteFile(filepath.Join(dir, "file"+string(rune('a'+i))+".txt"), []byte("Hello 😊\n"), 0600)
+	}
+
+	cmd := newTestCommand(false)
+	var err error
+	stderr := captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			err = DestroyEmojis(cmd, []string{dir})
+		})
+	})
+	if err != nil {
+		t.Fatalf("DestroyEmojis() error = %v", err)
+	}
+	if stderr != "" {
+		t.Errorf("Expected no progress output when stderr is not a terminal, got %q", stderr)
+	}
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"fmt"
+
+	"emoji-search-and-destroy/pkg/emoji"
+
+	"github.com/spf13/cobra"
+)
+
+// PrintRanges is the handler for the ranges subcommand. It prints the code point ranges
+// the detector treats as emojis, one U+XXXX-U+YYYY line each, followed by the tag
+// characters that are only removed after an emoji. With --bmp-only it prints the ranges
+// that flag leaves in effect.
+func PrintRanges(cmd *cobra.Command, args []string) error {
+	bmpOnly, err := cmd.Flags().GetBool("bmp-only")
+	if err != nil {
+		return fmt.Errorf("failed to get bmp-only flag: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	for _, r := range emoji.NewDetector().WithBMPOnly(bmpOnly).Ranges() {
+		if _, err := fmt.Fprintln(out, r); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(out, "%s (tag characters, only directly after an emoji)\n", emoji.TagRange())
+	return err
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func runPrintRanges(t *testing.T, bmpOnly bool) string {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("bmp-only", bmpOnly, "")
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	if err := PrintRanges(cmd, nil); err != nil {
+		t.Fatalf("PrintRanges() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestPrintRanges(t *testing.T) {
+	output := runPrintRanges(t, false)
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if lines[0] != "U+2600-U+27BF" {
+		t.Errorf("First line = %q, want %q", lines[0], "U+2600-U+27BF")
+	}
+	if !strings.Contains(output, "\nU+1F300-U+1F64F\n") {
+		t.Errorf("Output should contain the emoticons range, got %q", output)
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "U+E0020-U+E007F ") {
+		t.Errorf("Last line should describe the tag characters, got %q", lines[len(lines)-1])
+	}
+}
+
+func TestPrintRangesBMPOnly(t *testing.T) {
+	output := runPrintRanges(t, true)
+
+	if !strings.HasPrefix(output, "U+2600-U+27BF\n") {
+		t.Errorf("Output should start with the dingbats range, got %q", output)
+	}
+	if strings.Contains(output, "U+1F300-U+1F64F") {
+		t.Errorf("Output should not contain supplementary-plane ranges, got %q", output)
+	}
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+
+	"emoji-search-and-destroy/pkg/emoji"
+
+	"github.com/spf13/cobra"
+)
+
+// SelfCheck is the handler for the self-check subcommand. It runs the default detector
+// over the embedded golden corpus and returns an error listing each case it counted
+// differently than expected.
+func SelfCheck(cmd *cobra.Command, args []string) error {
+	return runSelfCheck(cmd, emoji.NewDetector())
+}
+
+// runSelfCheck runs the self-check with detector and reports the outcome on cmd's output
+func runSelfCheck(cmd *cobra.Command, detector *emoji.Detector) error {
+	cases, failures := detector.SelfCheck()
+	out := cmd.OutOrStdout()
+	if len(failures) == 0 {
+		_, err := fmt.Fprintf(out, "OK: all %d self-check case(s) passed\n", cases)
+		return err
+	}
+
+	for _, failure := range failures {
+		_, _ = fmt.Fprintf(out, "FAIL %s\n", failure)
+	}
+	cmd.SilenceUsage = true // The failures are already listed, so usage text would only add noise
+	return fmt.Errorf("self-check failed: %d of %d case(s)", len(failures), cases)
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"emoji-search-and-destroy/pkg/emoji"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSelfCheck(t *testing.T) {
+	cmd := &cobra.Command{}
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	if err := SelfCheck(cmd, nil); err != nil {
+		t.Fatalf("SelfCheck() error = %v\n%s", err, buf.String())
+	}
+	if !strings.HasPrefix(buf.String(), "OK: all ") {
+		t.Errorf("Output = %q, want an OK line", buf.String())
+	}
+
+	// Dropping the supplementary planes loses most of the corpus
+	buf.Reset()
+	err := runSelfCheck(cmd, emoji.NewDetector().WithBMPOnly(true))
+	if err == nil || !strings.HasPrefix(err.Error(), "self-check failed: ") {
+		t.Errorf("Expected a self-check failure, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "FAIL line ") {
+		t.Errorf("Output = %q, want FAIL lines", buf.String())
+	}
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+// outputFormatShort is the --output value for one "path: emoji emoji" line per file
+const outputFormatShort = "short"
+
+// outputShortResults writes each file with emojis as its path, a colon and the emojis
+// found separated by spaces, one line per file and with no header or totals, for
+// grep and awk
+func outputShortResults(out io.Writer, results []emoji.ProcessResult) error {
+	for _, result := range results {
+		if len(result.EmojisFound) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "%s: %s\n", result.FilePath, strings.Join(result.EmojisFound, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+func TestOutputShortResults(t *testing.T) {
+	results := []emoji.ProcessResult{
+		{FilePath: "docs/a.md", EmojisFound: []string{"🚀", "✨"}, Modified: true},
+		{FilePath: "clean.txt"},
+		{FilePath: "b: c.txt", EmojisFound: []string{"😊"}, Modified: true},
+	}
+
+	var out bytes.Buffer
+	if err := outputShortResults(&out, results); err != nil {
+		t.Fatalf("outputShortResults() error = %v", err)
+	}
+	if want := "docs/a.md: 🚀 ✨\nb: c.txt: 😊\n"; out.String() != want {
+		t.Errorf("Output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDestroyEmojisOutputShort(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "one.txt"), []byte("Hello 😊 World 🚀 again 😊"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "two.md"), []byte("# Done ✅"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("Nothing here"), 0600)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "short")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	want := filepath.Join(dir, "one.txt") + ": 😊 🚀\n" + filepath.Join(dir, "two.md") + ": ✅\n"
+	if output != want {
+		t.Errorf("Output = %q, want %q", output, want)
+	}
+
+	// A run without emojis prints nothing at all
+	cmd = newTestCommand(false)
+	_ = cmd.Flags().Set("output", "short")
+	output = captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{filepath.Join(dir, "clean.txt")}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+	if output != "" {
+		t.Errorf("Expected no output for a clean file, got %q", output)
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+// outputFormatTar is the --output value for a tar stream of cleaned files
+const outputFormatTar = "tar"
+
+// outputTar writes a tar archive of the cleaned content of every file the run would
+// modify, leaving the original tree untouched. Entry names are relative to root and
+// keep each file's mode and modification time. Protected files and files kept by
+// --refuse-empty would not be written, so they are left out.
+func outputTar(out io.Writer, processor *emoji.FileProcessor, results []emoji.ProcessResult, root string) error {
+	tw := tar.NewWriter(out)
+	for _, result := range results {
+		if !result.Modified || result.Protected || result.WriteRefused {
+			continue
+		}
+
+		info, err := os.Stat(result.FilePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(result.FilePath) // #nosec G304 -- path comes from the processed results
+		if err != nil {
+			return err
+		}
+		cleaned, err := processor.CleanFileContent(result.FilePath, content)
+		if err != nil {
+			return fmt.Errorf("failed to clean %s: %w", result.FilePath, err)
+		}
+
+		header := &tar.Header{
+			Name:    tarEntryName(root, result.FilePath),
+			Mode:    int64(info.Mode().Perm()),
+			Size:    int64(len(cleaned)),
+			ModTime: info.ModTime(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write tar header for %s: %w", result.FilePath, err)
+		}
+		if _, err := tw.Write(cleaned); err != nil {
+			return fmt.Errorf("failed to write tar entry for %s: %w", result.FilePath, err)
+		}
+	}
+	return tw.Close()
+}
+
+// tarEntryName returns the archive name for path: relative to root when it is inside
+// root, otherwise the path itself without any leading separator or parent references
+func tarEntryName(root, path string) string {
+	name, err := filepath.Rel(root, path)
+	if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		name = path
+	}
+	name = filepath.ToSlash(filepath.Clean(name))
+	for strings.HasPrefix(name, "../") {
+		name = strings.TrimPrefix(name, "../")
+	}
+	return strings.TrimLeft(name, "/")
+}
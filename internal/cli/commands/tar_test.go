@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"emoji-search-and-destroy/pkg/emoji"
+)
+
+func TestDestroyEmojisOutputTar(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "docs"), 0750)
+	files := map[string]string{
+		"README.md":     "Welcome 🚀",
+		"docs/guide.md": "Read me ✨ twice ✨",
+		"plain.txt":     "no emojis",
+	}
+	for name, content := range files {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	}
+	_ = os.Chmod(filepath.Join(dir, "docs/guide.md"), 0640)
+
+	cmd := newTestCommand(false)
+	_ = cmd.Flags().Set("output", "tar")
+	output := captureStdout(t, func() {
+		if err := DestroyEmojis(cmd, []string{dir}); err != nil {
+			t.Errorf("DestroyEmojis() error = %v", err)
+		}
+	})
+
+	entries := map[string]string{}
+	modes := map[string]int64{}
+	tr := tar.NewReader(strings.NewReader(output))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Invalid tar output: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		entries[header.Name] = string(content)
+		modes[header.Name] = header.Mode
+	}
+
+	want := map[string]string{
+		"README.md":     "Welcome ",
+		"docs/guide.md": "Read me  twice ",
+	}
+	if len(entries) != len(want) {
+		t.Errorf("Expected %d entries, got %q", len(want), entries)
+	}
+	detector := emoji.NewDetector()
+	for name, content := range want {
+		if entries[name] != content {
+			t.Errorf("%s = %q, want %q", name, entries[name], content)
+		}
+		if found := detector.FindEmojis(entries[name]); len(found) > 0 {
+			t.Errorf("%s still contains emojis: %q", name, found)
+		}
+	}
+	if modes["docs/guide.md"] != 0640 {
+		t.Errorf("docs/guide.md mode = %o, want 640", modes["docs/guide.md"])
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(content) != "Welcome 🚀" {
+		t.Errorf("Original file was modified: %q", content)
+	}
+}
+
+func TestDestroyEmojisOutputTarRequiresDryRun(t *testing.T) {
+	cmd := newTestCommand(true)
+	_ = cmd.Flags().Set("output", "tar")
+	if err := DestroyEmojis(cmd, []string{t.TempDir()}); err == nil || !strings.Contains(err.Error(), "--no-dry-run") {
+		t.Errorf("DestroyEmojis() error = %v, want a --no-dry-run error", err)
+	}
+}
+
+func TestTarEntryName(t *testing.T) {
+	tests := []struct {
+		root, path, expected string
+	}{
+		{"project", "project/docs/a.md", "docs/a.md"},
+		{".", "notes.md", "notes.md"},
+		{".", "/abs/path/notes.md", "abs/path/notes.md"},
+		{".", "../outside/notes.md", "outside/notes.md"},
+	}
+	for _, tt := range tests {
+		if got := tarEntryName(tt.root, tt.path); got != tt.expected {
+			t.Errorf("tarEntryName(%q, %q) = %q, want %q", tt.root, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestOutputTarSkipsUnwrittenResults(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	_ = os.WriteFile(path, []byte("Hi 😊"), 0600)
+
+	results := []emoji.ProcessResult{
+		{FilePath: path, Modified: true, Protected: true},
+		{FilePath: "zip://x.zip!a.md", Modified: false},
+	}
+	var out bytes.Buffer
+	if err := outputTar(&out, emoji.NewFileProcessor(), results, dir); err != nil {
+		t.Fatalf("outputTar() error = %v", err)
+	}
+	if _, err := tar.NewReader(&out).Next(); err != io.EOF {
+		t.Errorf("Expected an empty archive, got err = %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"emoji-search-and-destroy/pkg/emoji"
+
+	"github.com/spf13/cobra"
+)
+
+// VerifyCleaned is the handler for the verify subcommand. It checks that the cleaned
+// file (args[1]) differs from the original (args[0]) only by removed emojis and
+// whitespace, and returns an error listing every other difference.
+func VerifyCleaned(cmd *cobra.Command, args []string) error {
+	originalPath, cleanedPath := args[0], args[1]
+	original, err := os.ReadFile(originalPath) // #nosec G304 -- user-provided path to verify
+	if err != nil {
+		return fmt.Errorf("failed to read original file: %w", err)
+	}
+	cleaned, err := os.ReadFile(cleanedPath) // #nosec G304 -- user-provided path to verify
+	if err != nil {
+		return fmt.Errorf("failed to read cleaned file: %w", err)
+	}
+
+	if err := emoji.NewDetector().VerifyCleaned(string(original), string(cleaned)); err != nil {
+		cmd.SilenceUsage = true // The differences are the useful part, not usage text
+		return fmt.Errorf("%s: %w", cleanedPath, err)
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "OK: %s differs from %s only by removed emojis and whitespace\n", cleanedPath, originalPath)
+	return err
+}
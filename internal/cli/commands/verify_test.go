@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestVerifyCleaned(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.md")
+	_ = os.WriteFile(original, []byte("Ship it 🚀\nDone ✅\n"), 0600)
+
+	t.Run("only emojis removed", func(t *testing.T) {
+		cleaned := filepath.Join(dir, "cleaned.md")
+		_ = os.WriteFile(cleaned, []byte("Ship it\nDone\n"), 0600)
+
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := VerifyCleaned(cmd, []string{original, cleaned}); err != nil {
+			t.Fatalf("VerifyCleaned() error = %v", err)
+		}
+		if !strings.HasPrefix(buf.String(), "OK: ") {
+			t.Errorf("Output = %q, want an OK line", buf.String())
+		}
+	})
+
+	t.Run("non-emoji byte differs", func(t *testing.T) {
+		cleaned := filepath.Join(dir, "broken.md")
+		_ = os.WriteFile(cleaned, []byte("Ship it\nDome\n"), 0600)
+
+		err := VerifyCleaned(&cobra.Command{}, []string{original, cleaned})
+		if err == nil || !strings.Contains(err.Error(), `line 2, column 3: "n" became "m"`) {
+			t.Errorf("Expected the changed byte to be listed, got %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if err := VerifyCleaned(&cobra.Command{}, []string{original, filepath.Join(dir, "nope")}); err == nil {
+			t.Error("Expected an error for a missing cleaned file")
+		}
+	})
+}
@@ -17,6 +17,8 @@ This tool searches through all files in a directory and removes all emojis.
 By default, it runs in dry-run mode to preview changes. Use --no-dry-run to actually modify files.
 
 Use '-' as the directory to process content from stdin directly, or with --files-from-stdin to read file paths from stdin.
+Without a directory, the one named by the EMOJI_SAD_TARGET environment variable is used, or else the current directory.
+Use --text to clean a string given on the command line.
 
 Examples:
   # Preview emoji removal from current directory (dry-run)
@@ -31,33 +33,156 @@ Examples:
   # Process content from stdin directly
   cat file.txt | emoji-sad -
 
+  # Clean a string given on the command line
+  emoji-sad --text "Hello 😊"
+
   # Process file paths from stdin
   find . -name "*.txt" | emoji-sad - --files-from-stdin
 
   # Remove emojis from files listed in a file
   cat file_list.txt | emoji-sad - --files-from-stdin --no-dry-run
 
+  # Scan every directory listed in a file, with per-directory subtotals
+  emoji-sad --dirs-from nightly-dirs.txt
+
   # Exclude specific files or directories
   emoji-sad . --exclude node_modules --exclude "*.test.js"
   emoji-sad . --exclude /path/to/skip --exclude config.json
+  emoji-sad . --exclude-regex '.*/(test|spec)/.*'
+
+  # Fail in CI if any file would be changed
+  emoji-sad --check .
 
   # Output results in JSON format
   emoji-sad . --output json
   emoji-sad -l . -o json
-  find . -name "*.txt" | emoji-sad -o json -`,
-	Args: cobra.ExactArgs(1),
+  find . -name "*.txt" | emoji-sad -o json -
+
+  # Write a shareable HTML report
+  emoji-sad . --output html > report.html
+
+  # Report emojis inside gzip-compressed logs
+  emoji-sad --scan-gz /var/log/myapp
+
+  # Audit zip archives without extracting them
+  emoji-sad --scan-zip /path/to/releases`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// --text, --in-place-from and --dirs-from name the input directly, so no path argument is accepted
+		if cmd.Flags().Changed("text") || cmd.Flags().Changed("in-place-from") || cmd.Flags().Changed("dirs-from") {
+			return cobra.NoArgs(cmd, args)
+		}
+		// Without an argument the target comes from EMOJI_SAD_TARGET, or defaults to "."
+		return cobra.MaximumNArgs(1)(cmd, args)
+	},
 	RunE: commands.DestroyEmojis,
 }
 
+var rangesCmd = &cobra.Command{
+	Use:   "ranges",
+	Short: "Print the Unicode ranges treated as emojis",
+	Long: `Print the code point ranges the detector treats as emojis, one U+XXXX-U+YYYY line each,
+followed by the tag characters that are only removed directly after an emoji.`,
+	Args: cobra.NoArgs,
+	RunE: commands.PrintRanges,
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify ORIGINAL CLEANED",
+	Short: "Check that a cleaned file differs from its original only by removed emojis",
+	Long: `Compare a cleaned file with its original and fail, listing each difference, unless the
+only changes are removed emojis (with their joiners, variation selectors and tags) and
+removed whitespace.`,
+	Args: cobra.ExactArgs(2),
+	RunE: commands.VerifyCleaned,
+}
+
+var selfCheckCmd = &cobra.Command{
+	Use:   "self-check",
+	Short: "Check the detector against a built-in corpus of known emoji counts",
+	Long: `Count the emojis in each line of a corpus built into the binary and compare the counts with
+the expected ones, failing and listing each mismatch if the detected ranges have regressed.
+Useful as a smoke test after building.`,
+	Args: cobra.NoArgs,
+	RunE: commands.SelfCheck,
+}
+
 func init() {
 	rootCmd.Flags().Bool("no-dry-run", false, "Actually modify files instead of previewing")
 	rootCmd.Flags().BoolP("list-only", "l", false, "Only list files containing emojis, one per line")
+	rootCmd.Flags().Bool("relative", false, "Report paths relative to the target directory instead of as given (stdin and file lists keep their form)")
+	rootCmd.Flags().Bool("print0", false, "Terminate each path listed by --list-only or --check with a NUL byte instead of a newline (for xargs -0)")
 	rootCmd.Flags().StringSlice("exclude", []string{}, "Exclude files or directories matching these patterns (can be used multiple times)")
-	rootCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+	rootCmd.Flags().StringSlice("exclude-regex", []string{}, "Exclude paths matching these regular expressions (can be used multiple times)")
+	rootCmd.Flags().Bool("git-tracked-only", false, "Only process files tracked by git (from git ls-files); fails outside a git repository")
+	rootCmd.Flags().StringSlice("protect", []string{}, "Scan and report files matching these patterns but never modify them, even with --no-dry-run (can be used multiple times)")
+	rootCmd.Flags().Bool("warn-on-empty", false, "Warn about files whose cleaned content would be empty or whitespace-only")
+	rootCmd.Flags().Bool("refuse-empty", false, "Like --warn-on-empty, but also leave such files unmodified with --no-dry-run")
+	rootCmd.Flags().StringP("output", "o", "text", "Output format: text, json, html, junit, tar (cleaned copies of changed files, dry run only) or short (one \"path: emojis\" line per file)")
+	rootCmd.Flags().Bool("ascii-safe", false, "Keep text and JSON reports pure ASCII: non-ASCII characters are written as U+XXXX in text and \\uXXXX escapes in JSON")
+	rootCmd.Flags().Bool("gzip-output", false, "Gzip-compress the JSON report written to stdout (requires --output json)")
+	rootCmd.Flags().String("in-place-from", "", "Clean this file and, with --no-dry-run, replace it atomically (a safe alternative to '- < file > file')")
+	rootCmd.Flags().String("text", "", "Clean this text instead of reading files or stdin; prints the cleaned text to stdout")
+	rootCmd.Flags().Bool("manifest", false, "Read a manifest from stdin: file paths with optional per-file directives after a tab (implies --files-from-stdin)")
 	rootCmd.Flags().Bool("files-from-stdin", false, "Read file paths from stdin instead of processing stdin content directly")
+	rootCmd.Flags().String("dirs-from", "", "Scan each directory listed in this file, one per line (# comments), grouping the report by directory")
 	rootCmd.Flags().BoolP("quiet", "q", false, "Suppress processing reports (only output cleaned content for stdin)")
 	rootCmd.Flags().StringP("allow-file", "a", "", "File containing allowed emojis, one per line (default: .emoji-sad-allow if it exists)")
+	rootCmd.Flags().StringSlice("allow", []string{}, "Emoji to keep, as a literal or a U+XXXX code point, merged with the allow file (can be used multiple times)")
+	rootCmd.Flags().Bool("require-allow-file", false, "Fail if the allow file (explicit or default .emoji-sad-allow) is missing")
+	rootCmd.Flags().Bool("no-skip-binary", false, "Scan files with binary extensions too; only files that are not valid UTF-8 are skipped")
+	rootCmd.Flags().Bool("exclude-binary-by-mime", false, "Also skip files whose first 512 bytes are not sniffed as text/* (e.g. a PDF without a .pdf extension); may skip some unusual text files")
+	rootCmd.Flags().Bool("scan-gz", false, "Scan the decompressed contents of .gz files (report only, never rewritten)")
+	rootCmd.Flags().Bool("scan-zip", false, "Scan text entries inside .zip archives (report only, never rewritten)")
+	rootCmd.Flags().Bool("i-understand", false, "Confirm --no-dry-run on a git repository root")
+	rootCmd.Flags().BoolP("yes", "y", false, "Same as --i-understand")
+	rootCmd.Flags().String("structured", "", "Clean only string values in files of this format (yaml for .yaml/.yml, json for .json), never keys; files that fail to parse are skipped")
+	rootCmd.Flags().Bool("markdown-aware", false, "In .md and .markdown files, keep emojis inside fenced code blocks and inline code spans and clean only the prose")
+	rootCmd.Flags().Bool("group-clusters", false, "Report each emoji grapheme cluster (e.g. a ZWJ family, flag or skin-toned emoji) as one emoji; removal is unchanged")
+	rootCmd.Flags().StringSlice("never", []string{}, "Character never treated as an emoji, as a literal or a U+XXXX code point, while the rest of its range still is (can be used multiple times)")
+	rootCmd.Flags().Bool("bmp-only", false, "Only treat code points up to U+FFFF as emojis (symbols and dingbats such as U+2705), leaving supplementary-plane characters such as U+1F600 alone")
+	rootCmd.Flags().Bool("bidi-cleanup", false, "Also remove bidi embeddings, overrides and isolates left empty by emoji removal")
+	rootCmd.Flags().String("checkpoint", "", "Record processed files in this file so an interrupted directory scan resumes where it left off; removed on completion")
+	rootCmd.Flags().Bool("staged", false, "With --no-dry-run, build the cleaned tree in a staging directory and swap it in only if every file succeeds")
+	rootCmd.Flags().Bool("check", false, "Dry run that lists files that would be changed and exits 1 if there are any")
+	rootCmd.Flags().Bool("report-only", false, "Always exit 0 when emojis are found, even with --check, while still writing the full report")
+	rootCmd.Flags().Bool("assert-no-writes", false, "Debug: fail if any file write is attempted during a dry run")
+	rootCmd.Flags().Bool("trace", false, "Debug: log each non-ASCII character's code point and the rule that did or did not match it to stderr")
+	rootCmd.Flags().Bool("squeeze-blank-lines", false, "Collapse runs of blank lines left behind by removing emoji-only lines")
+	rootCmd.Flags().Bool("emoji-only-lines", false, "Report the lines whose only content is emojis")
+	rootCmd.Flags().Bool("delete-emoji-only-lines", false, "Delete lines whose only content is emojis instead of leaving them blank")
+	rootCmd.Flags().Bool("dedupe-across-run", false, "Skip files that are hard links to a file already processed in this run")
+	rootCmd.Flags().String("log-format", "text", "Format of the tool's own log records on stderr: text or json")
+	rootCmd.Flags().String("log-level", "warn", "Minimum level of log records: debug, info, warn or error")
+	rootCmd.Flags().Int("min-emojis", 0, "Only report files with at least N emoji occurrences; all files are still processed")
+	rootCmd.Flags().Float64("min-density", 0, "Only report files where emojis make up more than this percentage of the non-whitespace characters; all files are still processed")
+	rootCmd.Flags().String("json-indent", "2", "Indentation of JSON output: a number of spaces or 'tab'")
+	rootCmd.Flags().Bool("sort-emojis", false, "Sort each file's emoji list by code point in the output")
+	rootCmd.Flags().String("report-sort", "", "Order the files in the report by: path, count (most emojis first) or savings (most bytes removed first)")
+	rootCmd.Flags().String("column-mode", "rune", "How reported columns are counted: rune, byte or display (East Asian wide characters count as 2)")
+	rootCmd.Flags().Bool("hash-cleaned", false, "Include the SHA-256 of each file's cleaned content in the results, even in dry-run")
+	rootCmd.Flags().Bool("preview", false, "Show only the first emoji of each file and where it is, plus a count of the others")
+	rootCmd.Flags().Bool("include-mtime", false, "Include each file's modification time in the results")
+	rootCmd.Flags().Duration("since-mtime", 0, "Only process files modified within this duration, e.g. 168h (0 means no filter)")
+	rootCmd.Flags().String("min-file-size", "", "Skip files smaller than this size, e.g. 1 or 4KB (empty means no minimum)")
+	rootCmd.Flags().String("max-file-size", "", "Skip files larger than this size, e.g. 10MB (empty means no maximum)")
+	rootCmd.Flags().Int("max-lines", 0, "Skip files with more than this many lines (0 means no limit)")
+	rootCmd.Flags().Bool("skip-symlinks", false, "Skip symbolic links found while walking directories instead of processing their targets")
+	rootCmd.Flags().String("input-charset", "", "Decode files from this IANA charset, e.g. ISO-8859-1 or Shift_JIS, and encode cleaned files back to it (default: UTF-8)")
+	rootCmd.Flags().Int("limit", 0, "Stop after this many files containing emojis have been processed (0 means no limit)")
+	rootCmd.Flags().Int("workers", 0, "Process directory files concurrently with this many workers; files that cannot be read are then logged as warnings instead of failing the run (0 means one file at a time)")
+	rootCmd.Flags().Int("max-open-files", 0, "With --workers, cap how many files are open at once, e.g. on network filesystems (0 means one per worker)")
+	rootCmd.Flags().String("whitespace", "keep", "Handling of a space adjacent to removed emojis: keep, collapse-leading, collapse-trailing, collapse-both or collapse-between")
+	rootCmd.Flags().Bool("collapse-between", false, "Drop the single spaces between consecutive removed emojis, leaving one space where the run stood (same as --whitespace collapse-between)")
+	rootCmd.Flags().Bool("decode-html-entities", false, "Also detect and remove emojis written as HTML numeric entities (e.g. &#x1F600;)")
+	rootCmd.Flags().Bool("decode-escapes", false, `Also detect and remove emojis written as string escapes (e.g. \ud83d\ude00 or \u{1F600})`)
+	rootCmd.Flags().String("replace-map", "", "File of emoji=replacement lines; mapped emojis are substituted, others removed")
+	rootCmd.Flags().Bool("ascii", false, "Replace common emojis with plain-text equivalents (e.g. :) and <3) instead of removing them")
+	rootCmd.Flags().String("group-by", "", "Group the report by: dir (parent directory, with per-directory subtotals)")
 	rootCmd.Version = version.Version
+	rangesCmd.Flags().Bool("bmp-only", false, "Print only the ranges detected with --bmp-only")
+	rootCmd.AddCommand(rangesCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(selfCheckCmd)
 }
 
 // Execute runs the root command and returns any error encountered.
@@ -40,6 +40,18 @@ func TestRootCommand(t *testing.T) {
 	})
 }
 
+func TestRootCommandArgs(t *testing.T) {
+	if err := rootCmd.Args(rootCmd, nil); err != nil {
+		t.Errorf("Expected no argument to be accepted, got %v", err)
+	}
+	if err := rootCmd.Args(rootCmd, []string{"docs"}); err != nil {
+		t.Errorf("Expected one argument to be accepted, got %v", err)
+	}
+	if err := rootCmd.Args(rootCmd, []string{"docs", "src"}); err == nil {
+		t.Error("Expected two arguments to be rejected")
+	}
+}
+
 func TestExecute(t *testing.T) {
 	// Save original args
 	oldArgs := rootCmd.Args
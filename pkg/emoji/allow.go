@@ -0,0 +1,193 @@
+package emoji
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// AllowConfig is the content of an allow file: the global list of allowed emojis and
+// the lists that replace it for files with particular extensions.
+type AllowConfig struct {
+	Allowed []string
+
+	// ByExtension maps a lower-case extension, including the dot, to the emojis
+	// allowed in files with that extension. A present but empty list keeps none.
+	ByExtension map[string][]string
+}
+
+// ParseAllowList reads allowed emojis in the allow-file format: one emoji per line,
+// with surrounding whitespace trimmed. Blank lines and lines starting with # are
+// skipped. A line written as a shortcode, such as :check_mark:, allows the emoji it
+// names; an unknown shortcode is an error. Extension sections are rejected; use
+// ParseAllowConfig to read them.
+func ParseAllowList(r io.Reader) ([]string, error) {
+	config, err := ParseAllowConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.ByExtension) > 0 {
+		return nil, fmt.Errorf("extension sections are not supported here")
+	}
+	return config.Allowed, nil
+}
+
+// ParseAllowConfig reads an allow file that may contain extension sections. A line
+// such as [*.md] or [*.md, *.mdx] starts a section, and the emojis listed under it
+// are allowed in files with those extensions instead of the global list, which is
+// made of the lines before the first section. Lines are otherwise parsed as by
+// ParseAllowList, and an extension may appear in several sections.
+func ParseAllowConfig(r io.Reader) (AllowConfig, error) {
+	config := AllowConfig{}
+	var section []string // Extensions of the current section, nil for the global list
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		emoji := strings.TrimSpace(scanner.Text())
+		if emoji == "" || strings.HasPrefix(emoji, "#") { // Skip empty lines and comments
+			continue
+		}
+		if strings.HasPrefix(emoji, "[") && strings.HasSuffix(emoji, "]") {
+			exts, err := parseAllowSection(emoji[1 : len(emoji)-1])
+			if err != nil {
+				return AllowConfig{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			if config.ByExtension == nil {
+				config.ByExtension = make(map[string][]string)
+			}
+			for _, ext := range exts {
+				if _, ok := config.ByExtension[ext]; !ok {
+					config.ByExtension[ext] = []string{}
+				}
+			}
+			section = exts
+			continue
+		}
+		if isShortcode(emoji) {
+			resolved, ok := LookupShortcode(emoji)
+			if !ok {
+				return AllowConfig{}, fmt.Errorf("line %d: unknown shortcode %s", line, emoji)
+			}
+			emoji = resolved
+		}
+		if section == nil {
+			config.Allowed = append(config.Allowed, emoji)
+			continue
+		}
+		for _, ext := range section {
+			config.ByExtension[ext] = append(config.ByExtension[ext], emoji)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return AllowConfig{}, err
+	}
+
+	return config, nil
+}
+
+// parseAllowSection parses the comma-separated patterns of a section header, each
+// written as *.ext, into lower-case extensions with their leading dot.
+func parseAllowSection(header string) ([]string, error) {
+	var exts []string
+	for _, pattern := range strings.Split(header, ",") {
+		pattern = strings.TrimSpace(pattern)
+		ext := strings.TrimPrefix(pattern, "*")
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 || strings.ContainsAny(ext[1:], "./*?[]") {
+			return nil, fmt.Errorf("invalid section pattern %q: want *.ext", pattern)
+		}
+		exts = append(exts, strings.ToLower(ext))
+	}
+	return exts, nil
+}
+
+// NewDetectorWithAllowedFrom creates a new emoji detector with the allowed emojis read
+// from r, which is parsed as by ParseAllowList.
+func NewDetectorWithAllowedFrom(r io.Reader) (*Detector, error) {
+	allowed, err := ParseAllowList(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewDetectorWithAllowed(allowed), nil
+}
+
+// allowedSequences indexes the allowed entries of more than one code point by their
+// first rune, longest first so the longest match wins.
+func allowedSequences(allowed map[string]bool) map[rune][]string {
+	var seqs map[rune][]string
+	for emoji := range allowed {
+		if utf8.RuneCountInString(emoji) < 2 {
+			continue
+		}
+		if seqs == nil {
+			seqs = make(map[rune][]string)
+		}
+		r, _ := utf8.DecodeRuneInString(emoji)
+		seqs[r] = append(seqs[r], emoji)
+	}
+	for _, list := range seqs {
+		sort.Slice(list, func(i, j int) bool {
+			if len(list[i]) != len(list[j]) {
+				return len(list[i]) > len(list[j])
+			}
+			return list[i] < list[j]
+		})
+	}
+	return seqs
+}
+
+// allowedSequenceLen returns the byte length of the allowed multi-rune sequence at the
+// start of s, whose first rune is r, or 0 if there is none.
+func (d *Detector) allowedSequenceLen(r rune, s string) int {
+	for _, seq := range d.allowedSeqs[r] {
+		if strings.HasPrefix(s, seq) {
+			return len(seq)
+		}
+	}
+	return 0
+}
+
+// allowedSequenceRunes is allowedSequenceLen for text held as runes, returning the
+// number of runes in the matching sequence.
+func (d *Detector) allowedSequenceRunes(runes []rune) int {
+	for _, seq := range d.allowedSeqs[runes[0]] {
+		n := utf8.RuneCountInString(seq)
+		if n <= len(runes) && string(runes[:n]) == seq {
+			return n
+		}
+	}
+	return 0
+}
+
+// detectorFor returns the Detector for the file at path: a copy of fp.Detector with
+// the allow list for its extension when AllowByExtension has one, else fp.Detector.
+// Copies are cached per extension until fp.Detector is replaced.
+func (fp *FileProcessor) detectorFor(path string) *Detector {
+	ext := strings.ToLower(filepath.Ext(path))
+	allowed, ok := fp.AllowByExtension[ext]
+	if !ok {
+		return fp.Detector
+	}
+
+	fp.extMu.Lock()
+	defer fp.extMu.Unlock()
+	if fp.extBase != fp.Detector {
+		fp.extDetectors = make(map[string]*Detector)
+		fp.extBase = fp.Detector
+	}
+	detector, ok := fp.extDetectors[ext]
+	if !ok {
+		detector = fp.Detector.Clone().WithAllowed(allowed)
+		fp.extDetectors[ext] = detector
+	}
+	return detector
+}
+
+// CleanFileText is CleanText for the content of the file at path, using the allow
+// list AllowByExtension gives for its extension.
+func (fp *FileProcessor) CleanFileText(path, text string) string {
+	return fp.postProcess(text, fp.removeEmojis(fp.detectorFor(path), text))
+}
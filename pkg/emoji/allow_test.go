@@ -0,0 +1,220 @@
+package emoji
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAllowList(t *testing.T) {
+	input := "# Emojis we keep\n✅\n\n   \n  ❌  \n#🚀 commented out\n⚠️\n"
+	allowed, err := ParseAllowList(bytes.NewReader([]byte(input)))
+	if err != nil {
+		t.Fatalf("ParseAllowList() error = %v", err)
+	}
+	if want := []string{"✅", "❌", "⚠️"}; !reflect.DeepEqual(allowed, want) {
+		t.Errorf("ParseAllowList() = %q, want %q", allowed, want)
+	}
+}
+
+func TestParseAllowList_Shortcodes(t *testing.T) {
+	allowed, err := ParseAllowList(bytes.NewReader([]byte(":check_mark_button:\n🚀\n  :red_heart:  \n")))
+	if err != nil {
+		t.Fatalf("ParseAllowList() error = %v", err)
+	}
+	if want := []string{"✅", "🚀", "❤"}; !reflect.DeepEqual(allowed, want) {
+		t.Errorf("ParseAllowList() = %q, want %q", allowed, want)
+	}
+
+	detector := NewDetectorWithAllowed(allowed)
+	if got, want := detector.RemoveEmojis("✅ ship 🚀 I ❤️ Go ❌"), "✅ ship 🚀 I ❤️ Go "; got != want {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, want)
+	}
+
+	if _, err := ParseAllowList(bytes.NewReader([]byte("✅\n:no_such_emoji:\n"))); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an unknown shortcode error for line 2, got %v", err)
+	}
+}
+
+func TestParseAllowConfig(t *testing.T) {
+	input := "✅\n\n[*.md, *.MDX]\n🚀\n:check_mark_button:\n[*.go]\n# nothing kept in Go files\n[*.md]\n❌\n"
+	config, err := ParseAllowConfig(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAllowConfig() error = %v", err)
+	}
+	if want := []string{"✅"}; !reflect.DeepEqual(config.Allowed, want) {
+		t.Errorf("Allowed = %q, want %q", config.Allowed, want)
+	}
+	want := map[string][]string{
+		".md":  {"🚀", "✅", "❌"},
+		".mdx": {"🚀", "✅"},
+		".go":  {},
+	}
+	if !reflect.DeepEqual(config.ByExtension, want) {
+		t.Errorf("ByExtension = %q, want %q", config.ByExtension, want)
+	}
+
+	for _, input := range []string{"[md]\n", "[*.md, docs/*]\n", "[*]\n"} {
+		if _, err := ParseAllowConfig(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("ParseAllowConfig(%q) error = %v, want an invalid section error", input, err)
+		}
+	}
+	if _, err := ParseAllowList(strings.NewReader("[*.md]\n✅\n")); err == nil {
+		t.Error("Expected ParseAllowList to reject extension sections")
+	}
+}
+
+func TestFileProcessor_AllowByExtension(t *testing.T) {
+	dir := t.TempDir()
+	content := "✅ done 🚀 shipped\n"
+	for _, name := range []string{"notes.txt", "README.md", "guide.MD"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := NewFileProcessorWithExcludesAndAllowed(nil, []string{"✅"})
+	fp.AllowByExtension = map[string][]string{".md": {"🚀"}}
+	if _, err := fp.ProcessDirectory(dir, false); err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"notes.txt": "✅ done  shipped\n",
+		"README.md": " done 🚀 shipped\n",
+		"guide.MD":  " done 🚀 shipped\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// Replacing the Detector drops the per-extension copies made from the old one
+	fp.Detector = NewDetector().WithReplacements(map[string]string{"✅": "[done]"})
+	if got, want := fp.CleanFileText("x.md", content), "[done] done 🚀 shipped\n"; got != want {
+		t.Errorf("CleanFileText() = %q, want %q", got, want)
+	}
+}
+
+func TestLookupShortcode(t *testing.T) {
+	for _, name := range []string{":check_mark:", "check_mark"} {
+		if got, ok := LookupShortcode(name); !ok || got != "✔" {
+			t.Errorf("LookupShortcode(%q) = %q, %v, want %q", name, got, ok, "✔")
+		}
+	}
+	for name, emoji := range shortcodes {
+		if !isEmoji([]rune(emoji)[0]) || len([]rune(emoji)) != 1 {
+			t.Errorf("Shortcode %s maps to %q, which is not a single detected emoji", name, emoji)
+		}
+	}
+}
+
+func TestNewDetectorWithAllowedFrom(t *testing.T) {
+	detector, err := NewDetectorWithAllowedFrom(bytes.NewReader([]byte("# keep\n✅\n\n❌\n")))
+	if err != nil {
+		t.Fatalf("NewDetectorWithAllowedFrom() error = %v", err)
+	}
+	if got, want := detector.RemoveEmojis("✅ done ❌ todo 🚀 launch"), "✅ done ❌ todo  launch"; got != want {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, want)
+	}
+	if found := detector.FindEmojis("✅ ❌ 🚀"); !reflect.DeepEqual(found, []string{"🚀"}) {
+		t.Errorf("FindEmojis() = %q, want [🚀]", found)
+	}
+}
+
+// failingReader returns an error on every read.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestNewDetectorWithAllowedFrom_ReadError(t *testing.T) {
+	if _, err := NewDetectorWithAllowedFrom(failingReader{}); err == nil {
+		t.Error("Expected an error from a failing reader")
+	}
+}
+
+func TestDetector_AllowedSequences(t *testing.T) {
+	allowed := []string{"🇺🇸", "👨‍👩‍👧", "❤️", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F"}
+	tests := []struct {
+		name    string
+		input   string
+		cleaned string
+		found   []string
+	}{
+		{"allowed flag survives", "Made in 🇺🇸 and 🇨🇦", "Made in 🇺🇸 and ", []string{"🇨", "🇦"}},
+		{"allowed zero width joiner family survives", "Family 👨‍👩‍👧 and 👨", "Family 👨‍👩‍👧 and ", []string{"👨"}},
+		{"members of an allowed sequence alone are removed", "👩 👧", " ", []string{"👩", "👧"}},
+		{"allowed variation sequence survives", "I ❤️ Go, not ❤", "I ❤️ Go, not ", []string{"❤"}},
+		{"allowed tag sequence survives", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F 🏴", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F ", []string{"🏴"}},
+	}
+
+	detectors := map[string]*Detector{
+		"default":    NewDetectorWithAllowed(allowed),
+		"regex only": NewDetectorRegexOnly().WithAllowed(allowed),
+		"clone":      NewDetectorWithAllowed(allowed).Clone(),
+	}
+	for name, detector := range detectors {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				if got := detector.RemoveEmojis(tt.input); got != tt.cleaned {
+					t.Errorf("RemoveEmojis(%q) = %q, want %q", tt.input, got, tt.cleaned)
+				}
+				if got := detector.FindEmojis(tt.input); !reflect.DeepEqual(got, tt.found) {
+					t.Errorf("FindEmojis(%q) = %q, want %q", tt.input, got, tt.found)
+				}
+				if got := detector.Count(tt.input); got != len(tt.found) {
+					t.Errorf("Count(%q) = %d, want %d", tt.input, got, len(tt.found))
+				}
+			})
+		}
+	}
+}
+
+func TestDetector_AllowedSequencesCollapsingSpaces(t *testing.T) {
+	detector := NewDetectorWithAllowed([]string{"🇺🇸"}).WithWhitespacePolicy(WhitespaceCollapseBoth)
+	if got, want := detector.RemoveEmojis("Go 🇺🇸 team 🚀 now"), "Go 🇺🇸 team now"; got != want {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, want)
+	}
+	if got, want := detector.RemoveEmojisExcept("Go 🇺🇸 team", map[string]bool{"🇺🇸": true}), "Go 🇺🇸 team"; got != want {
+		t.Errorf("RemoveEmojisExcept() = %q, want %q", got, want)
+	}
+}
+
+// largeAllowList is a few hundred allow entries, a quarter of them multi-rune
+// sequences, as in a big allow file.
+func largeAllowList() []string {
+	var allowed []string
+	for r := rune(0x1F300); r < 0x1F300+300; r++ {
+		allowed = append(allowed, string(r))
+		if r%4 == 0 {
+			allowed = append(allowed, string(r)+string(zeroWidthJoiner)+string(r+1))
+		}
+	}
+	return append(allowed, "🇺🇸", "❤️")
+}
+
+func BenchmarkRemoveEmojisLargeAllowList(b *testing.B) {
+	detector := NewDetectorWithAllowed(largeAllowList())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.RemoveEmojis(benchmarkText)
+	}
+}
+
+func BenchmarkFindEmojisLargeAllowList(b *testing.B) {
+	detector := NewDetectorWithAllowed(largeAllowList())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.FindEmojis(benchmarkText)
+	}
+}
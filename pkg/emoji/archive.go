@@ -0,0 +1,116 @@
+package emoji
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// scanGzipFile decompresses a .gz file in memory and reports any emojis found.
+// Compressed files are never rewritten, so the result is never marked modified.
+func (fp *FileProcessor) scanGzipFile(filePath string) (ProcessResult, error) {
+	file, err := os.Open(filePath) // #nosec G304 -- filePath is user-provided directory path
+	if err != nil {
+		return ProcessResult{FilePath: filePath}, fmt.Errorf("%w: %w", ErrReadFile, err)
+	}
+	defer func() {
+		_ = file.Close() // Ignore close error in defer
+	}()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return ProcessResult{FilePath: filePath}, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer func() {
+		_ = reader.Close() // Ignore close error in defer
+	}()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return ProcessResult{FilePath: filePath}, fmt.Errorf("failed to decompress file: %w", err)
+	}
+
+	detector := fp.detectorFor(strings.TrimSuffix(filePath, ".gz"))
+	return ProcessResult{
+		FilePath:     filePath,
+		EmojisFound:  detector.FindEmojis(string(content)),
+		OriginalSize: int64(len(content)),
+		Modified:     false,
+		Occurrences:  fp.countOccurrences(detector, string(content)),
+		Density:      fp.density(detector, string(content)),
+	}, nil
+}
+
+// isScannableGzip reports whether a path is a .gz file that should be scanned.
+func (fp *FileProcessor) isScannableGzip(path string) bool {
+	return fp.ScanGzip && filepath.Ext(path) == ".gz"
+}
+
+// IsScannableZip reports whether a path is a .zip archive that should be scanned.
+func (fp *FileProcessor) IsScannableZip(path string) bool {
+	return fp.ScanZip && filepath.Ext(path) == ".zip"
+}
+
+// ScanZipArchive opens a .zip archive read-only and reports emojis found in its text
+// entries. Each result's FilePath has the form zip://archive.zip!entry. Only entries
+// containing emojis are returned, and archives are never rewritten.
+func (fp *FileProcessor) ScanZipArchive(archivePath string) ([]ProcessResult, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer func() {
+		_ = reader.Close() // Ignore close error in defer
+	}()
+
+	var results []ProcessResult
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() || skipExtensions[filepath.Ext(entry.Name)] {
+			continue
+		}
+
+		content, err := readZipEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip entry %s: %w", entry.Name, err)
+		}
+
+		// Only text entries are scanned
+		if !utf8.Valid(content) {
+			continue
+		}
+
+		detector := fp.detectorFor(entry.Name)
+		emojis := detector.FindEmojis(string(content))
+		if len(emojis) == 0 {
+			continue
+		}
+
+		results = append(results, ProcessResult{
+			FilePath:     "zip://" + archivePath + "!" + entry.Name,
+			EmojisFound:  emojis,
+			OriginalSize: int64(len(content)),
+			Modified:     false,
+			Occurrences:  fp.countOccurrences(detector, string(content)),
+			Density:      fp.density(detector, string(content)),
+		})
+	}
+
+	return results, nil
+}
+
+// readZipEntry returns the uncompressed contents of a single zip entry.
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close() // Ignore close error in defer
+	}()
+	return io.ReadAll(rc)
+}
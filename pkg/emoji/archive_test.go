@@ -0,0 +1,151 @@
+package emoji
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileProcessor_ScanGzip(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_gzip_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte("log line 🔥 here"))
+	_ = gz.Close()
+
+	gzPath := filepath.Join(tempDir, "app.log.gz")
+	if err := os.WriteFile(gzPath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("skipped by default", func(t *testing.T) {
+		processor := NewFileProcessor()
+		results, err := processor.ProcessDirectory(tempDir, true)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected .gz file to be skipped, got %d results", len(results))
+		}
+	})
+
+	t.Run("reported with ScanGzip", func(t *testing.T) {
+		processor := NewFileProcessor()
+		processor.ScanGzip = true
+		results, err := processor.ProcessDirectory(tempDir, false)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+		if !reflect.DeepEqual(results[0].EmojisFound, []string{"🔥"}) {
+			t.Errorf("EmojisFound = %v, want [🔥]", results[0].EmojisFound)
+		}
+		if results[0].Modified {
+			t.Error("Compressed files should never be marked modified")
+		}
+
+		// The compressed file must be left untouched even without dry-run
+		content, _ := os.ReadFile(gzPath) // #nosec G304 -- gzPath is controlled in test
+		if !bytes.Equal(content, buf.Bytes()) {
+			t.Error("Compressed file was rewritten")
+		}
+	})
+
+	t.Run("invalid gzip data", func(t *testing.T) {
+		badPath := filepath.Join(tempDir, "bad.gz")
+		_ = os.WriteFile(badPath, []byte("not gzip"), 0600)
+		defer func() { _ = os.Remove(badPath) }()
+
+		processor := NewFileProcessor()
+		processor.ScanGzip = true
+		if _, err := processor.ProcessFile(badPath, true); err == nil {
+			t.Error("Expected error for invalid gzip data")
+		}
+	})
+}
+
+func TestFileProcessor_ScanZip(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_zip_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	entries := map[string]string{
+		"docs/readme.md": "Release notes 🚀",
+		"clean.txt":      "no emojis here",
+		"image.png":      "binary 🎉 by extension",
+	}
+	for name, content := range entries {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(content))
+	}
+	_ = zw.Close()
+
+	zipPath := filepath.Join(tempDir, "bundle.zip")
+	if err := os.WriteFile(zipPath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("skipped by default", func(t *testing.T) {
+		processor := NewFileProcessor()
+		results, err := processor.ProcessDirectory(tempDir, true)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected .zip file to be skipped, got %d results", len(results))
+		}
+	})
+
+	t.Run("entries reported with ScanZip", func(t *testing.T) {
+		processor := NewFileProcessor()
+		processor.ScanZip = true
+		results, err := processor.ProcessDirectory(tempDir, false)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d: %v", len(results), results)
+		}
+
+		expectedPath := "zip://" + zipPath + "!docs/readme.md"
+		if results[0].FilePath != expectedPath {
+			t.Errorf("FilePath = %q, want %q", results[0].FilePath, expectedPath)
+		}
+		if !reflect.DeepEqual(results[0].EmojisFound, []string{"🚀"}) {
+			t.Errorf("EmojisFound = %v, want [🚀]", results[0].EmojisFound)
+		}
+
+		// The archive must be left untouched even without dry-run
+		content, _ := os.ReadFile(zipPath) // #nosec G304 -- zipPath is controlled in test
+		if !bytes.Equal(content, buf.Bytes()) {
+			t.Error("Zip archive was rewritten")
+		}
+	})
+
+	t.Run("invalid zip data", func(t *testing.T) {
+		processor := NewFileProcessor()
+		processor.ScanZip = true
+		badPath := filepath.Join(tempDir, "bad.zip")
+		_ = os.WriteFile(badPath, []byte("not a zip"), 0600)
+		defer func() { _ = os.Remove(badPath) }()
+
+		if _, err := processor.ScanZipArchive(badPath); err == nil {
+			t.Error("Expected error for invalid zip data")
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package emoji
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// variationSelector16 requests emoji presentation for the preceding character, as in ❤️.
+const variationSelector16 = '\uFE0F'
+
+// asciiFallbacks maps common emojis to plain-text equivalents.
+var asciiFallbacks = map[rune]string{
+	'😀': ":D",
+	'😃': ":D",
+	'😄': ":D",
+	'😁': ":D",
+	'😊': ":)",
+	'🙂': ":)",
+	'😉': ";)",
+	'😛': ":P",
+	'😜': ";P",
+	'😐': ":|",
+	'😮': ":O",
+	'😞': ":(",
+	'🙁': ":(",
+	'☹': ":(",
+	'😢': ":'(",
+	'😎': "B)",
+	'❤': "<3",
+	'💔': "</3",
+	'👍': "+1",
+	'👎': "-1",
+	'✅': "[x]",
+	'✔': "[x]",
+	'❌': "[ ]",
+	'⚠': "[!]",
+	'➡': "->",
+}
+
+// ReplaceWithASCII replaces emojis that have a plain-text equivalent (for example
+// 😊 becomes ":)" and ❤️ becomes "<3") and removes the rest, leaving allowed emojis
+// untouched. Replacements set with WithReplacements take precedence over the built-in
+// table. A variation selector following a replaced emoji is dropped with it.
+func (d *Detector) ReplaceWithASCII(text string) string {
+	text = d.removeEncodedEmojis(text)
+
+	var out strings.Builder
+	out.Grow(len(text))
+
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		if !found || d.allowedEmojis[emoji] {
+			out.WriteString(emoji)
+			i += size
+			continue
+		}
+
+		i += size
+		if replacement, ok := d.asciiReplacement(emoji); ok {
+			out.WriteString(replacement)
+			if next, nextSize := utf8.DecodeRuneInString(text[i:]); next == variationSelector16 {
+				i += nextSize
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// asciiReplacement returns what ReplaceWithASCII turns emoji into: its entry set with
+// WithReplacements, else its plain-text equivalent. It reports false for emojis that
+// are removed.
+func (d *Detector) asciiReplacement(emoji string) (string, bool) {
+	if replacement, ok := d.replacements[emoji]; ok {
+		return replacement, true
+	}
+	r, _ := utf8.DecodeRuneInString(emoji)
+	replacement, ok := asciiFallbacks[r]
+	return replacement, ok
+}
+
+// Substitutions returns what each emoji in text that is replaced rather than removed
+// becomes, keyed by the emoji. With ascii set it describes ReplaceWithASCII, otherwise
+// RemoveEmojis, which only substitutes emojis set with WithReplacements. It returns nil
+// if nothing in text would be substituted.
+func (d *Detector) Substitutions(text string, ascii bool) map[string]string {
+	if !ascii && len(d.replacements) == 0 {
+		return nil
+	}
+
+	var substitutions map[string]string
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		i += size
+		if !found || d.allowedEmojis[emoji] {
+			continue
+		}
+
+		replacement, ok := d.replacements[emoji]
+		if ascii {
+			replacement, ok = d.asciiReplacement(emoji)
+		}
+		if ok {
+			if substitutions == nil {
+				substitutions = make(map[string]string)
+			}
+			substitutions[emoji] = replacement
+		}
+	}
+	return substitutions
+}
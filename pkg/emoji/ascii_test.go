@@ -0,0 +1,50 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector_ReplaceWithASCII(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		input    string
+		expected string
+	}{
+		{"no emojis", nil, "plain text", "plain text"},
+		{"mapped emoji", nil, "Thanks 😊", "Thanks :)"},
+		{"heart with variation selector", nil, "I ❤️ Go", "I <3 Go"},
+		{"heart without variation selector", nil, "I ❤ Go", "I <3 Go"},
+		{"unmapped emoji removed", nil, "Launch 🚀 now", "Launch  now"},
+		{"mapped and unmapped", nil, "✅ done 🎉 👍", "[x] done  +1"},
+		{"allowed emoji kept", []string{"✅"}, "✅ ok ❌ bad", "✅ ok [ ] bad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetectorWithAllowed(tt.allowed)
+			if result := detector.ReplaceWithASCII(tt.input); result != tt.expected {
+				t.Errorf("ReplaceWithASCII(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetector_Substitutions(t *testing.T) {
+	text := "✅ ship 🚀 I ❤️ 😊 🎉 ✅"
+
+	detector := NewDetectorWithAllowed([]string{"😊"}).WithReplacements(map[string]string{"🚀": ":rocket:"})
+	if got, want := detector.Substitutions(text, false), map[string]string{"🚀": ":rocket:"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Substitutions() = %q, want %q", got, want)
+	}
+
+	want := map[string]string{"✅": "[x]", "🚀": ":rocket:", "❤": "<3"}
+	if got := detector.Substitutions(text, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("Substitutions(ascii) = %q, want %q", got, want)
+	}
+
+	if got := NewDetector().Substitutions(text, false); got != nil {
+		t.Errorf("Substitutions() without replacements = %q, want nil", got)
+	}
+}
@@ -0,0 +1,79 @@
+package emoji
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// pendingWrite is a cleaned file waiting for its batch to be flushed.
+type pendingWrite struct {
+	path string
+	data []byte
+	perm os.FileMode
+}
+
+// writeBatch buffers the files cleaned by ProcessDirectoryContext when WriteBatchSize
+// is set. Workers keep cleaning while a full batch is written, each file still
+// replaced atomically, so reads and writes overlap instead of alternating per file.
+type writeBatch struct {
+	size      int
+	workers   int
+	openFiles *semaphore.Weighted
+
+	mu      sync.Mutex
+	pending []pendingWrite
+}
+
+// add queues a cleaned file for writing.
+func (b *writeBatch) add(w pendingWrite) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, w)
+}
+
+// take removes and returns the queued files, or nil if fewer than atLeast are queued.
+func (b *writeBatch) take(atLeast int) []pendingWrite {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.pending) == 0 || len(b.pending) < atLeast {
+		return nil
+	}
+	writes := b.pending
+	b.pending = nil
+	return writes
+}
+
+// flushFull writes the queued files if a whole batch has built up.
+func (b *writeBatch) flushFull() error {
+	return b.write(b.take(b.size))
+}
+
+// flush writes all queued files.
+func (b *writeBatch) flush() error {
+	return b.write(b.take(1))
+}
+
+// write writes the given files in parallel, holding an open-file slot for each. It does not
+// stop on cancellation: these files are already reported as modified.
+func (b *writeBatch) write(writes []pendingWrite) error {
+	group := new(errgroup.Group)
+	group.SetLimit(b.workers)
+	for _, w := range writes {
+		group.Go(func() error {
+			if err := b.openFiles.Acquire(context.Background(), 1); err != nil {
+				return err
+			}
+			defer b.openFiles.Release(1)
+			if err := writeFileAtomic(w.path, w.data, w.perm); err != nil {
+				return fmt.Errorf("failed to write %s: %w", w.path, err)
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
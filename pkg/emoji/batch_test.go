@@ -0,0 +1,103 @@
+package emoji
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTinyFiles creates n small files in dir, every other one containing an emoji.
+func writeTinyFiles(tb testing.TB, dir string, n int) {
+	tb.Helper()
+	for i := 0; i < n; i++ {
+		content := "plain text"
+		if i%2 == 0 {
+			content = "Hello 😊"
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%03d.txt", i)), []byte(content), 0600); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_WriteBatchSize(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTinyFiles(t, tempDir, 45)
+
+	fp := NewFileProcessor()
+	fp.Workers = 4
+	fp.WriteBatchSize = 8 // 23 files to write, so the last batch is partial
+	results, err := fp.ProcessDirectoryContext(context.Background(), tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectoryContext() error = %v", err)
+	}
+	if len(results) != 23 {
+		t.Fatalf("Expected 23 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		content, err := os.ReadFile(result.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "Hello " {
+			t.Errorf("%s not cleaned: %q", result.FilePath, content)
+		}
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 45 {
+		t.Errorf("Expected 45 files and no temporary files left behind, got %d entries", len(entries))
+	}
+	if fp.batch != nil {
+		t.Error("Batch should be cleared after ProcessDirectoryContext returns")
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_WriteBatchSizeDryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTinyFiles(t, tempDir, 10)
+
+	fp := NewFileProcessor()
+	fp.WriteBatchSize = 4
+	fp.AssertNoWrites = true
+	if _, err := fp.ProcessDirectoryContext(context.Background(), tempDir, true); err != nil {
+		t.Fatalf("ProcessDirectoryContext() error = %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(tempDir, "file000.txt"))
+	if string(content) != "Hello 😊" {
+		t.Errorf("Dry run modified a file: %q", content)
+	}
+}
+
+func benchmarkWriteBatchSize(b *testing.B, batchSize int) {
+	dir := b.TempDir()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		writeTinyFiles(b, dir, 2000)
+		fp := NewFileProcessor()
+		fp.AtomicWrites = true
+		fp.WriteBatchSize = batchSize
+		b.StartTimer()
+
+		if _, err := fp.ProcessDirectoryContext(context.Background(), dir, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWriteUnbatched and BenchmarkWriteBatched clean 2000 tiny files, half of
+// them written back, with per-file atomic writes and with batched writes.
+func BenchmarkWriteUnbatched(b *testing.B) {
+	benchmarkWriteBatchSize(b, 0)
+}
+
+func BenchmarkWriteBatched(b *testing.B) {
+	benchmarkWriteBatchSize(b, 64)
+}
@@ -0,0 +1,46 @@
+package emoji
+
+import "regexp"
+
+// bidiRunRegex matches a directional embedding, override or isolate together with
+// its terminator, with no other bidi formatting characters inside. Embeddings and
+// overrides (LRE, RLE, LRO, RLO) end with PDF; isolates (LRI, RLI, FSI) end with PDI.
+var bidiRunRegex = regexp.MustCompile(`[\x{202A}\x{202B}\x{202D}\x{202E}]([^\x{202A}-\x{202E}\x{2066}-\x{2069}]*)\x{202C}|[\x{2066}-\x{2068}]([^\x{202A}-\x{202E}\x{2066}-\x{2069}]*)\x{2069}`)
+
+// WithBidiCleanup makes RemoveEmojis also drop directional embeddings, overrides and
+// isolates whose only content was emojis, so no empty bidi run is left behind, and
+// returns the Detector. Bidi marks and controls are never removed otherwise.
+func (d *Detector) WithBidiCleanup(enabled bool) *Detector {
+	d.bidiCleanup = enabled
+	return d
+}
+
+// removeEmptiedBidiRuns drops the bidi runs in text that removing emojis would leave
+// empty, control characters included. Runs that were already empty are kept.
+func (d *Detector) removeEmptiedBidiRuns(text string) string {
+	return bidiRunRegex.ReplaceAllStringFunc(text, func(run string) string {
+		groups := bidiRunRegex.FindStringSubmatch(run)
+		content := groups[1] + groups[2] // Only one of the alternatives matched
+		if content != "" && d.onlyRemovableEmojis(content) {
+			return ""
+		}
+		return run
+	})
+}
+
+// onlyRemovableEmojis reports whether RemoveEmojis would delete all of text, that is,
+// text consists solely of emojis that are neither allowed nor replaced.
+func (d *Detector) onlyRemovableEmojis(text string) bool {
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		if !found || d.allowedEmojis[emoji] {
+			return false
+		}
+		if _, ok := d.replacements[emoji]; ok {
+			return false
+		}
+		i += size
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package emoji
+
+import "testing"
+
+func TestDetector_BidiMarksPreserved(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"hebrew with RLM marks", "שלום \u200F😊\u200F עולם", "שלום \u200F\u200F עולם"},
+		{"arabic in RTL embedding", "\u202Bمرحبا 😊\u202C done", "\u202Bمرحبا \u202C done"},
+		{"RTL override and isolate", "\u202Eabc 🚀\u202C \u2067שלום 🎉\u2069", "\u202Eabc \u202C \u2067שלום \u2069"},
+		{"LRM around emoji", "\u200E😊\u200E", "\u200E\u200E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, detector := range []*Detector{NewDetector(), NewDetector().WithBidiCleanup(true)} {
+				if result := detector.RemoveEmojis(tt.input); result != tt.expected {
+					t.Errorf("RemoveEmojis(%q) with bidi cleanup %v = %q, want %q", tt.input, detector.bidiCleanup, result, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDetector_WithBidiCleanup(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		plain   string
+		cleanup string
+	}{
+		{"emoji-only embedding", "a \u202B😊\u202C b", "a \u202B\u202C b", "a  b"},
+		{"emoji-only isolate", "\u2067🎉🚀\u2069!", "\u2067\u2069!", "!"},
+		{"embedding with text", "\u202Bשלום 😊\u202C", "\u202Bשלום \u202C", "\u202Bשלום \u202C"},
+		{"already empty embedding", "\u202B\u202C 😊", "\u202B\u202C ", "\u202B\u202C "},
+		{"mismatched terminator", "\u2067😊\u202C", "\u2067\u202C", "\u2067\u202C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := NewDetector().RemoveEmojis(tt.input); result != tt.plain {
+				t.Errorf("Without cleanup: RemoveEmojis(%q) = %q, want %q", tt.input, result, tt.plain)
+			}
+			if result := NewDetector().WithBidiCleanup(true).RemoveEmojis(tt.input); result != tt.cleanup {
+				t.Errorf("With cleanup: RemoveEmojis(%q) = %q, want %q", tt.input, result, tt.cleanup)
+			}
+		})
+	}
+
+	// An embedding holding an allowed or replaced emoji is not emptied
+	allowed := NewDetectorWithAllowed([]string{"😊"}).WithBidiCleanup(true)
+	if result := allowed.RemoveEmojis("\u202B😊\u202C"); result != "\u202B😊\u202C" {
+		t.Errorf("Allowed emoji embedding = %q", result)
+	}
+	replaced := NewDetector().WithReplacements(map[string]string{"🚀": "[ship]"}).WithBidiCleanup(true)
+	if result := replaced.RemoveEmojis("\u202B🚀\u202C"); result != "\u202B[ship]\u202C" {
+		t.Errorf("Replaced emoji embedding = %q", result)
+	}
+}
@@ -0,0 +1,25 @@
+package emoji
+
+// maxBMPRune is the last code point of the Basic Multilingual Plane.
+const maxBMPRune = 0xFFFF
+
+// bmpRanges is the part of emojiRanges at or below U+FFFF.
+var bmpRanges = func() []runeRange {
+	var ranges []runeRange
+	for _, rr := range emojiRanges {
+		if rr.lo > maxBMPRune {
+			break // Ranges are sorted, so no later range is in the BMP
+		}
+		ranges = append(ranges, runeRange{rr.lo, min(rr.hi, maxBMPRune)})
+	}
+	return ranges
+}()
+
+// WithBMPOnly restricts detection to code points at or below U+FFFF and returns the
+// Detector. Symbols and dingbats such as ✅ still match, but supplementary-plane
+// characters such as 😀 are left alone, for text where they are not real emojis.
+func (d *Detector) WithBMPOnly(enabled bool) *Detector {
+	d.bmpOnly = enabled
+	d.rebuildRanges()
+	return d
+}
@@ -0,0 +1,35 @@
+package emoji
+
+import "testing"
+
+func TestDetector_WithBMPOnly(t *testing.T) {
+	d := NewDetector().WithBMPOnly(true)
+	text := "Done ✅ and happy 😀"
+
+	if got, want := d.RemoveEmojis(text), "Done  and happy 😀"; got != want {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, want)
+	}
+	if got := d.FindEmojis(text); len(got) != 1 || got[0] != "✅" {
+		t.Errorf("FindEmojis() = %q, want [✅]", got)
+	}
+	if got := d.Count(text); got != 1 {
+		t.Errorf("Count() = %d, want 1", got)
+	}
+	for _, r := range d.Ranges() {
+		if r.Hi > maxBMPRune {
+			t.Errorf("Range %s extends past the BMP", r)
+		}
+	}
+
+	d.WithBMPOnly(false)
+	if got, want := d.RemoveEmojis(text), "Done  and happy "; got != want {
+		t.Errorf("RemoveEmojis() after WithBMPOnly(false) = %q, want %q", got, want)
+	}
+}
+
+func TestDetector_WithBMPOnlyRegexOnly(t *testing.T) {
+	d := NewDetectorRegexOnly().WithBMPOnly(true)
+	if got := d.FindEmojis("✅ 😀"); len(got) != 1 || got[0] != "✅" {
+		t.Errorf("FindEmojis() = %q, want [✅]", got)
+	}
+}
@@ -0,0 +1,53 @@
+package emoji
+
+import (
+	"fmt"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// LookupCharset returns the encoding registered with IANA under name, such as
+// "ISO-8859-1" or "Shift_JIS". Names and aliases are matched case-insensitively.
+func LookupCharset(name string) (encoding.Encoding, error) {
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil || enc == nil {
+		return nil, fmt.Errorf("unsupported charset: %s", name)
+	}
+	return enc, nil
+}
+
+// decodeInput returns file content as text, decoded from InputCharset when it is set.
+func (fp *FileProcessor) decodeInput(content []byte) (string, error) {
+	if fp.InputCharset == nil {
+		return string(content), nil
+	}
+	decoded, err := fp.InputCharset.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode content: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// encodeOutput returns cleaned text as file content, encoded to InputCharset when it
+// is set. Text the charset cannot represent is an error rather than being replaced.
+func (fp *FileProcessor) encodeOutput(text string) ([]byte, error) {
+	if fp.InputCharset == nil {
+		return []byte(text), nil
+	}
+	encoded, err := fp.InputCharset.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cleaned content: %w", err)
+	}
+	return encoded, nil
+}
+
+// CleanFileContent cleans the raw content of the file at path as ProcessFile would,
+// decoding it from and encoding it back to InputCharset when that is set.
+func (fp *FileProcessor) CleanFileContent(path string, content []byte) ([]byte, error) {
+	text, err := fp.decodeInput(content)
+	if err != nil {
+		return nil, err
+	}
+	return fp.encodeOutput(fp.CleanFileText(path, text))
+}
@@ -0,0 +1,75 @@
+package emoji
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileProcessor_InputCharsetShiftJIS(t *testing.T) {
+	charset, err := LookupCharset("shift_jis")
+	if err != nil {
+		t.Fatalf("LookupCharset() error = %v", err)
+	}
+
+	// "日本語のテキスト" followed by a line break, in Shift-JIS
+	content := []byte("\x93\xfa\x96\x7b\x8c\xea\x82\xcc\x83\x65\x83\x4c\x83\x58\x83\x67" + "\r\n")
+	file := filepath.Join(t.TempDir(), "legacy.txt")
+	if err := os.WriteFile(file, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.InputCharset = charset
+	result, err := fp.ProcessFile(file, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if len(result.EmojisFound) != 0 || result.Modified {
+		t.Errorf("Expected no emojis in the decoded text, got %+v", result)
+	}
+	if got, _ := os.ReadFile(file); !bytes.Equal(got, content) {
+		t.Errorf("File bytes changed: got %x, want %x", got, content)
+	}
+}
+
+func TestFileProcessor_InputCharsetRoundTrip(t *testing.T) {
+	charset, err := LookupCharset("UTF-16LE")
+	if err != nil {
+		t.Fatalf("LookupCharset() error = %v", err)
+	}
+	encode := func(s string) []byte {
+		b, err := charset.NewEncoder().Bytes([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	file := filepath.Join(t.TempDir(), "wide.txt")
+	if err := os.WriteFile(file, encode("launch 🚀 now\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.InputCharset = charset
+	result, err := fp.ProcessFile(file, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if len(result.EmojisFound) != 1 || result.EmojisFound[0] != "🚀" {
+		t.Errorf("EmojisFound = %q, want [🚀]", result.EmojisFound)
+	}
+	want := encode("launch  now\n")
+	if got, _ := os.ReadFile(file); !bytes.Equal(got, want) {
+		t.Errorf("File = %x, want %x", got, want)
+	}
+	if result.OriginalSize != 28 || result.NewSize != int64(len(want)) {
+		t.Errorf("Sizes = %d → %d, want 28 → %d", result.OriginalSize, result.NewSize, len(want))
+	}
+
+	if _, err := LookupCharset("no-such-charset"); err == nil {
+		t.Error("Expected an error for an unknown charset")
+	}
+}
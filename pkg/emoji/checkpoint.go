@@ -0,0 +1,115 @@
+package emoji
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// checkpointFlushEvery is how many recorded paths are buffered before the
+// checkpoint file is flushed to disk.
+const checkpointFlushEvery = 100
+
+// Checkpoint persists the paths processed by a directory walk so an interrupted
+// run can be resumed. The file holds one path per line and is only appended to.
+// It is safe for concurrent use.
+type Checkpoint struct {
+	path string
+	done map[string]bool
+
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	pending int
+	err     error
+}
+
+// OpenCheckpoint opens the checkpoint at path, loading the paths recorded by a
+// previous run. The file is created if it does not exist.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	done := make(map[string]bool)
+
+	content, err := os.ReadFile(path) // #nosec G304 -- path is the user-provided checkpoint file
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if line != "" {
+			done[filepath.Clean(line)] = true
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304 -- path is the user-provided checkpoint file
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	return &Checkpoint{path: path, done: done, file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Done reports whether path was recorded by this or a previous run.
+func (c *Checkpoint) Done(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[filepath.Clean(path)]
+}
+
+// Record marks path as processed. Records are flushed every checkpointFlushEvery
+// paths and on Close; the first write error is kept and returned by Close.
+func (c *Checkpoint) Record(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if c.done[path] || c.err != nil {
+		return
+	}
+	c.done[path] = true
+
+	if _, err := c.writer.WriteString(path + "\n"); err != nil {
+		c.err = fmt.Errorf("failed to write checkpoint: %w", err)
+		return
+	}
+	c.pending++
+	if c.pending >= checkpointFlushEvery {
+		c.err = c.flush()
+	}
+}
+
+// flush writes buffered records to the checkpoint file. The caller holds c.mu.
+func (c *Checkpoint) flush() error {
+	c.pending = 0
+	if err := c.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close flushes any buffered records and closes the checkpoint file, keeping it
+// for a later run to resume from.
+func (c *Checkpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.err
+	if err == nil {
+		err = c.flush()
+	}
+	if closeErr := c.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close checkpoint: %w", closeErr)
+	}
+	return err
+}
+
+// Remove closes and deletes the checkpoint file once a run has completed.
+func (c *Checkpoint) Remove() error {
+	_ = c.Close() // The records are about to be discarded
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package emoji
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint_ResumeSkipsDoneFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "tree")
+	if err := os.Mkdir(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"a.txt", "b.txt", "c.txt"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("Hello 😊"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkpointPath := filepath.Join(tempDir, "scan.checkpoint")
+
+	// First run stops after one file, standing in for an interruption
+	checkpoint, err := OpenCheckpoint(checkpointPath)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	fp := NewFileProcessor()
+	fp.Checkpoint = checkpoint
+	fp.Limit = 1
+	results, err := fp.ProcessDirectory(dir, false)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("First run: %d results, error = %v", len(results), err)
+	}
+	if err := checkpoint.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Restore the processed file's emoji so a reprocessed file would be noticed
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Hello 😊"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, err = OpenCheckpoint(checkpointPath)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	if !checkpoint.Done(filepath.Join(dir, "a.txt")) {
+		t.Fatal("Expected a.txt to be recorded by the first run")
+	}
+
+	fp = NewFileProcessor()
+	fp.Checkpoint = checkpoint
+	var processed []string
+	fp.OnFileProcessed = func(path string) { processed = append(processed, filepath.Base(path)) }
+	if _, err := fp.ProcessDirectory(dir, false); err != nil {
+		t.Fatalf("Resumed run error = %v", err)
+	}
+	if len(processed) != 2 || processed[0] != "b.txt" || processed[1] != "c.txt" {
+		t.Errorf("Resumed run processed %v, want [b.txt c.txt]", processed)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(content) != "Hello 😊" {
+		t.Errorf("a.txt was processed again: %q", content)
+	}
+
+	if err := checkpoint.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed, stat error = %v", err)
+	}
+}
+
+func TestCheckpoint_FlushesPeriodically(t *testing.T) {
+	checkpointPath := filepath.Join(t.TempDir(), "scan.checkpoint")
+	checkpoint, err := OpenCheckpoint(checkpointPath)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	defer checkpoint.Close()
+
+	for i := 0; i < checkpointFlushEvery; i++ {
+		checkpoint.Record(fmt.Sprintf("file-%d.txt", i))
+	}
+
+	// Without Close, a new reader sees everything recorded up to the last flush
+	reopened, err := OpenCheckpoint(checkpointPath)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	defer reopened.Close()
+	if len(reopened.done) != checkpointFlushEvery {
+		t.Errorf("Reopened checkpoint has %d paths, want %d", len(reopened.done), checkpointFlushEvery)
+	}
+}
@@ -0,0 +1,191 @@
+package emoji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// errLimitReached stops a concurrent walk once Limit results have been collected.
+var errLimitReached = errors.New("limit reached")
+
+// walkItem is a file selected by the directory walk for a worker to process.
+type walkItem struct {
+	path    string
+	modTime time.Time
+}
+
+// ProcessDirectoryContext is a concurrent ProcessDirectory. One goroutine walks dirPath
+// while Workers goroutines process the files it selects. The first hard error cancels
+// the walk and the other workers and is returned promptly, together with the results
+// collected so far. Files that cannot be read are soft failures: they are recorded in
+// Warnings and processing continues. Results are sorted by path. With MaxOpenFiles
+// set, at most that many workers are reading or writing a file at any one time. With
+// WriteBatchSize set and dryRun false, cleaned files are written in batches.
+func (fp *FileProcessor) ProcessDirectoryContext(ctx context.Context, dirPath string, dryRun bool) ([]ProcessResult, error) {
+	group, ctx := errgroup.WithContext(ctx)
+	items := make(chan walkItem)
+
+	group.Go(func() error {
+		defer close(items)
+		return filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			process, modTime, err := fp.walkFilter(path, d)
+			if !process {
+				return err
+			}
+
+			select {
+			case items <- walkItem{path: path, modTime: modTime}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	})
+
+	var mu sync.Mutex // Guards results and Warnings
+	var results []ProcessResult
+	collect := func(found ...ProcessResult) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, result := range found {
+			if fp.LimitReached(len(results)) {
+				break
+			}
+			results = append(results, result)
+		}
+		if fp.LimitReached(len(results)) {
+			return errLimitReached
+		}
+		return nil
+	}
+
+	workers := fp.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	openFiles := semaphore.NewWeighted(int64(workers))
+	if fp.MaxOpenFiles > 0 {
+		openFiles = semaphore.NewWeighted(int64(fp.MaxOpenFiles))
+	}
+	// A slot is held for each file in flight and each result kept, so however many
+	// workers run, no more than Limit files are written
+	var slots *semaphore.Weighted
+	if fp.Limit > 0 {
+		slots = semaphore.NewWeighted(int64(fp.Limit))
+	}
+	if fp.WriteBatchSize > 0 && !dryRun {
+		fp.batch = &writeBatch{size: fp.WriteBatchSize, workers: workers, openFiles: openFiles}
+		defer func() { fp.batch = nil }()
+	}
+	for i := 0; i < workers; i++ {
+		group.Go(func() error {
+			for item := range items {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if slots != nil {
+					if err := slots.Acquire(ctx, 1); err != nil {
+						return err
+					}
+				}
+				if err := openFiles.Acquire(ctx, 1); err != nil {
+					return err
+				}
+				found, err := fp.processWalkItem(item, dryRun)
+				openFiles.Release(1)
+				if slots != nil {
+					found = holdSlots(slots, found)
+				}
+				if errors.Is(err, ErrReadFile) {
+					mu.Lock()
+					fp.Warnings = append(fp.Warnings, fmt.Errorf("skipped %s: %w", item.path, err))
+					mu.Unlock()
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to process %s: %w", item.path, err)
+				}
+				if err := collect(found...); err != nil {
+					return err
+				}
+				if fp.batch != nil {
+					if err := fp.batch.flushFull(); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	err := group.Wait()
+	if errors.Is(err, errLimitReached) {
+		err = nil
+	}
+	if fp.batch != nil {
+		// Files already cleaned are reported as modified, so write them even after an error
+		if flushErr := fp.batch.flush(); err == nil {
+			err = flushErr
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].FilePath < results[j].FilePath
+	})
+	return results, err
+}
+
+// holdSlots keeps the slot taken for an item only if the item produced results, and
+// takes one more for each further result, as a zip archive can give several. Results
+// no slot is left for are dropped.
+func holdSlots(slots *semaphore.Weighted, found []ProcessResult) []ProcessResult {
+	if len(found) == 0 {
+		slots.Release(1)
+		return nil
+	}
+	kept := 1
+	for kept < len(found) && slots.TryAcquire(1) {
+		kept++
+	}
+	return found[:kept]
+}
+
+// processWalkItem processes a single selected file or archive and returns the results
+// that contained emojis.
+func (fp *FileProcessor) processWalkItem(item walkItem, dryRun bool) ([]ProcessResult, error) {
+	if fp.IsScannableZip(item.path) {
+		results, err := fp.ScanZipArchive(item.path)
+		if err == nil {
+			fp.fileProcessed(item.path)
+		}
+		return results, err
+	}
+
+	result, err := fp.ProcessFile(item.path, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	fp.fileProcessed(item.path)
+	if fp.IncludeModTime {
+		result.ModifiedTime = item.modTime
+	}
+
+	if len(result.EmojisFound) == 0 {
+		return nil, nil
+	}
+	return []ProcessResult{result}, nil
+}
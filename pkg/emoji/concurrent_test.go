@@ -0,0 +1,216 @@
+package emoji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileProcessor_ProcessDirectoryContext(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		content := "plain text"
+		if i%2 == 0 {
+			content = "Hello 😊"
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%02d.txt", i)), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := NewFileProcessor()
+	fp.Workers = 4
+	results, err := fp.ProcessDirectoryContext(context.Background(), tempDir, true)
+	if err != nil {
+		t.Fatalf("ProcessDirectoryContext() error = %v", err)
+	}
+
+	sequential, _ := NewFileProcessor().ProcessDirectory(tempDir, true)
+	if len(results) != len(sequential) || len(results) != 10 {
+		t.Fatalf("Expected 10 results matching ProcessDirectory, got %d and %d", len(results), len(sequential))
+	}
+	for i := range results {
+		if results[i].FilePath != sequential[i].FilePath {
+			t.Errorf("Result %d = %s, want %s", i, results[i].FilePath, sequential[i].FilePath)
+		}
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_LimitWrites(t *testing.T) {
+	for run := 0; run < 3; run++ {
+		tempDir := t.TempDir()
+		for i := 0; i < 20; i++ {
+			if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%02d.txt", i)), []byte("Hello 😊"), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		fp := NewFileProcessor()
+		fp.Workers = 4
+		fp.Limit = 3
+		// Hold each worker after its write so they all get one in before any
+		// result is collected
+		fp.OnFileProcessed = func(string) { time.Sleep(10 * time.Millisecond) }
+		results, err := fp.ProcessDirectoryContext(context.Background(), tempDir, false)
+		if err != nil {
+			t.Fatalf("ProcessDirectoryContext() error = %v", err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, got %d", len(results))
+		}
+
+		reported := make(map[string]bool)
+		for _, result := range results {
+			reported[result.FilePath] = true
+		}
+		entries, err := os.ReadDir(tempDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modified := 0
+		for _, entry := range entries {
+			path := filepath.Join(tempDir, entry.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "Hello 😊" {
+				modified++
+				if !reported[path] {
+					t.Errorf("%s was modified but not reported", path)
+				}
+			}
+		}
+		if modified != 3 {
+			t.Fatalf("Expected 3 files modified on disk, got %d", modified)
+		}
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_FatalErrorCancels(t *testing.T) {
+	tempDir := t.TempDir()
+	const total = 200
+	for i := 0; i < total; i++ {
+		if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%03d.txt", i)), []byte("Hello 😊"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Walked first, and not valid gzip data, so processing it is a hard error
+	if err := os.WriteFile(filepath.Join(tempDir, "000-broken.gz"), []byte("not gzip"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var processed atomic.Int64
+	fp := NewFileProcessor()
+	fp.ScanGzip = true
+	fp.Workers = 2
+	fp.OnFileProcessed = func(string) {
+		processed.Add(1)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	results, err := fp.ProcessDirectoryContext(context.Background(), tempDir, true)
+	if err == nil || !strings.Contains(err.Error(), "000-broken.gz") {
+		t.Fatalf("Expected the broken archive error, got %v", err)
+	}
+	if processed.Load() >= total {
+		t.Errorf("Expected the group to cancel before processing every file, processed %d", processed.Load())
+	}
+	if len(results) >= total {
+		t.Errorf("Expected partial results, got %d", len(results))
+	}
+	for _, result := range results {
+		if len(result.EmojisFound) == 0 {
+			t.Errorf("Unexpected result without emojis: %s", result.FilePath)
+		}
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_SoftWarnings(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "a.txt")
+	second := filepath.Join(tempDir, "b.txt")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte("Hello 😊"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := NewFileProcessor()
+	fp.Workers = 1
+	// Remove b.txt after the walk has selected it but before it is read
+	fp.OnFileProcessed = func(path string) {
+		if path == first {
+			time.Sleep(50 * time.Millisecond)
+			_ = os.Remove(second)
+		}
+	}
+
+	results, err := fp.ProcessDirectoryContext(context.Background(), tempDir, true)
+	if err != nil {
+		t.Fatalf("Expected unreadable files to be warnings, got error %v", err)
+	}
+	if len(results) != 1 || results[0].FilePath != first {
+		t.Errorf("Expected only %s in results, got %v", first, results)
+	}
+	if len(fp.Warnings) != 1 || !errors.Is(fp.Warnings[0], ErrReadFile) {
+		t.Errorf("Expected one read warning, got %v", fp.Warnings)
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_Cancelled(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("Hello 😊"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewFileProcessor().ProcessDirectoryContext(ctx, tempDir, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryContext_MaxOpenFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		if err := os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%02d.txt", i)), []byte("Hello 😊"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Count files open in ReadFile, holding each open for a moment, and track the peak
+	var open, peak atomic.Int64
+	fp := NewFileProcessor()
+	fp.ReadFile = func(filePath string) ([]byte, error) {
+		n := open.Add(1)
+		defer open.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		return os.ReadFile(filePath) // #nosec G304 -- test file in a temp directory
+	}
+	fp.Workers = 8
+	fp.MaxOpenFiles = 2
+
+	results, err := fp.ProcessDirectoryContext(context.Background(), tempDir, true)
+	if err != nil {
+		t.Fatalf("ProcessDirectoryContext() error = %v", err)
+	}
+	if len(results) != 40 {
+		t.Errorf("Expected 40 results, got %d", len(results))
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Peak open files = %d, want at most 2", got)
+	}
+}
@@ -0,0 +1,69 @@
+package emoji
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ContextMatch is an emoji occurrence with the text around it on the same line.
+type ContextMatch struct {
+	Emoji  string `json:"emoji"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+	Line   int    `json:"line"`   // 1-based
+	Column int    `json:"column"` // 1-based, in runes
+}
+
+// FindEmojisWithContext returns every emoji occurrence in text, in order, with up to
+// radius runes of text before and after it. The context never crosses a line break,
+// so an emoji at the start or end of a line has less of it, and a trailing carriage
+// return is left out. Allowed emojis are skipped and encoded emojis are not reported,
+// as with EmojiSpans.
+func (d *Detector) FindEmojisWithContext(text string, radius int) []ContextMatch {
+	var matches []ContextMatch
+	line, lineStart, counted := 1, 0, 0
+
+	for _, span := range d.EmojiSpans(text) {
+		start, end := span[0], span[1]
+		line += strings.Count(text[counted:start], "\n")
+		if i := strings.LastIndexByte(text[counted:start], '\n'); i >= 0 {
+			lineStart = counted + i + 1
+		}
+		counted = start
+
+		matches = append(matches, ContextMatch{
+			Emoji:  text[start:end],
+			Before: contextBefore(text[lineStart:start], radius),
+			After:  contextAfter(text[end:], radius),
+			Line:   line,
+			Column: utf8.RuneCountInString(text[lineStart:start]) + 1,
+		})
+	}
+
+	return matches
+}
+
+// contextBefore returns up to radius runes from the end of prefix, which holds the
+// text from the start of the line.
+func contextBefore(prefix string, radius int) string {
+	start := len(prefix)
+	for n := 0; n < radius && start > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(prefix[:start])
+		start -= size
+	}
+	return prefix[start:]
+}
+
+// contextAfter returns up to radius runes from the start of rest, stopping at the
+// end of the line.
+func contextAfter(rest string, radius int) string {
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = strings.TrimSuffix(rest[:i], "\r")
+	}
+	end := 0
+	for n := 0; n < radius && end < len(rest); n++ {
+		_, size := utf8.DecodeRuneInString(rest[end:])
+		end += size
+	}
+	return rest[:end]
+}
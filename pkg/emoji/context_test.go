@@ -0,0 +1,38 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector_FindEmojisWithContext(t *testing.T) {
+	text := "🚀 launch day\r\nthe café ✅ is open\nall done 🚀\n"
+	got := NewDetector().FindEmojisWithContext(text, 4)
+	want := []ContextMatch{
+		{Emoji: "🚀", Before: "", After: " lau", Line: 1, Column: 1},
+		{Emoji: "✅", Before: "afé ", After: " is ", Line: 2, Column: 10},
+		{Emoji: "🚀", Before: "one ", After: "", Line: 3, Column: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindEmojisWithContext() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDetector_FindEmojisWithContext_Radius(t *testing.T) {
+	detector := NewDetectorWithAllowed([]string{"✅"})
+	text := "ab✅🚀🚀cd"
+
+	got := detector.FindEmojisWithContext(text, 0)
+	if len(got) != 2 || got[0].Before != "" || got[0].After != "" {
+		t.Errorf("FindEmojisWithContext(radius 0) = %+v, want two matches without context", got)
+	}
+
+	got = detector.FindEmojisWithContext(text, 10)
+	want := []ContextMatch{
+		{Emoji: "🚀", Before: "ab✅", After: "🚀cd", Line: 1, Column: 4},
+		{Emoji: "🚀", Before: "ab✅🚀", After: "cd", Line: 1, Column: 5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindEmojisWithContext() = %+v, want %+v", got, want)
+	}
+}
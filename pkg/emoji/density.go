@@ -0,0 +1,30 @@
+package emoji
+
+import "unicode"
+
+// Density returns the emojis in text, counted as Count does, as a percentage of its
+// non-whitespace characters. Joiners, variation selectors and tag characters are not
+// counted as characters, so text made only of emojis has a density of 100.
+func (d *Detector) Density(text string) float64 {
+	emojis := d.Count(text)
+	if emojis == 0 {
+		return 0
+	}
+
+	characters := 0
+	for _, r := range text {
+		if unicode.IsSpace(r) || r == zeroWidthJoiner || r == variationSelector16 || isTagRune(r) {
+			continue
+		}
+		characters++
+	}
+	return min(100, 100*float64(emojis)/float64(max(characters, 1)))
+}
+
+// density returns the emoji density d finds in text, or zero unless RecordDensity is set.
+func (fp *FileProcessor) density(d *Detector, text string) float64 {
+	if !fp.RecordDensity {
+		return 0
+	}
+	return d.Density(text)
+}
@@ -0,0 +1,31 @@
+package emoji
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDetector_Density(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"no emojis here", 0},
+		{"😊", 100},
+		{"👨‍👩‍👧 ❤️ 🏴󠁧󠁢󠁳󠁣󠁴󠁿", 100},
+		{"ok 😊", 100.0 / 3},
+		{"🎉🎉 go\n\tgo 🚀", 300.0 / 7},
+	}
+
+	detector := NewDetector()
+	for _, tt := range tests {
+		if got := detector.Density(tt.text); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Density(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	if got := NewDetectorWithAllowed([]string{"✅"}).Density("✅ done 😊"); math.Abs(got-100.0/6) > 1e-9 {
+		t.Errorf("Density() with an allowed emoji = %v, want %v", got, 100.0/6)
+	}
+}
@@ -2,39 +2,187 @@
 package emoji
 
 import (
+	"fmt"
+	"io"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
+// WhitespacePolicy controls how a single space adjacent to a removed emoji is handled.
+type WhitespacePolicy string
+
+const (
+	// WhitespaceKeep leaves surrounding spaces untouched.
+	WhitespaceKeep WhitespacePolicy = "keep"
+	// WhitespaceCollapseLeading drops one space immediately before a removed emoji.
+	WhitespaceCollapseLeading WhitespacePolicy = "collapse-leading"
+	// WhitespaceCollapseTrailing drops one space immediately after a removed emoji.
+	WhitespaceCollapseTrailing WhitespacePolicy = "collapse-trailing"
+	// WhitespaceCollapseBoth drops one adjacent space on either side of a removed emoji,
+	// preferring the trailing one so the surrounding words stay separated.
+	WhitespaceCollapseBoth WhitespacePolicy = "collapse-both"
+	// WhitespaceCollapseBetween drops the single spaces separating consecutive removed
+	// emojis and merges the spaces around such a run into one, so "a 😊 🚀 b" becomes
+	// "a b". Spaces next to a lone removed emoji are kept.
+	WhitespaceCollapseBetween WhitespacePolicy = "collapse-between"
+)
+
+// ParseWhitespacePolicy converts a string to a WhitespacePolicy.
+func ParseWhitespacePolicy(s string) (WhitespacePolicy, error) {
+	switch policy := WhitespacePolicy(s); policy {
+	case WhitespaceKeep, WhitespaceCollapseLeading, WhitespaceCollapseTrailing, WhitespaceCollapseBoth, WhitespaceCollapseBetween:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid whitespace policy: %s (must be 'keep', 'collapse-leading', 'collapse-trailing', 'collapse-both' or 'collapse-between')", s)
+}
+
+// runeRange is a range of code points treated as emojis. Both bounds are inclusive.
+type runeRange struct {
+	lo, hi rune
+}
+
+// emojiRanges is the canonical table of emoji code point ranges. Both the regex and
+// the per-rune checks are derived from it. Ranges are sorted, never overlap and are
+// never adjacent, so each code point is covered by at most one entry.
+var emojiRanges = []runeRange{
+	{0x2600, 0x27BF},   // Miscellaneous Symbols, Dingbats
+	{0x1F018, 0x1F0FF}, // Mahjong Tiles (partial), Domino Tiles, Playing Cards
+	{0x1F170, 0x1F171}, // Enclosed Alphanumeric Supplement emojis: A and B buttons
+	{0x1F17E, 0x1F17F}, // O and P buttons
+	{0x1F18E, 0x1F18E}, // AB button
+	{0x1F191, 0x1F19A}, // Squared CL through VS
+	{0x1F1E0, 0x1F1FF}, // Regional Indicator Symbols
+	{0x1F300, 0x1F64F}, // Misc Symbols and Pictographs, Emoticons
+	{0x1F680, 0x1F6FF}, // Transport and Map Symbols
+	{0x1F900, 0x1FA73}, // Supplemental Symbols and Pictographs, Chess Symbols (including xiangqi), Symbols and Pictographs Extended-A
+	{0x1FA78, 0x1FA7A},
+	{0x1FA80, 0x1FA82},
+	{0x1FA90, 0x1FA95},
+}
+
+// minEmojiRune is the lowest code point in emojiRanges, used to skip ordinary text quickly.
+const minEmojiRune = 0x2600
+
+// emojiPattern builds a regex alternation matching any rune in the given ranges.
+func emojiPattern(ranges []runeRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, rr := range ranges {
+		parts = append(parts, fmt.Sprintf(`[\x{%X}-\x{%X}]`, rr.lo, rr.hi))
+	}
+	return strings.Join(parts, "|")
+}
+
+// compileEmojiRegex builds the regex matching one emoji from ranges and any tag sequence after it.
+// With no ranges left, for example when WithNever excludes them all, the regex matches nothing
+// rather than the empty string at every position.
+func compileEmojiRegex(ranges []runeRange) *regexp.Regexp {
+	if len(ranges) == 0 {
+		return regexp.MustCompile(`[^\x00-\x{10FFFF}]`)
+	}
+	return regexp.MustCompile("(?:" + emojiPattern(ranges) + ")" + tagSequencePattern)
+}
+
 // Detector provides methods for finding and removing emojis from text.
 type Detector struct {
 	emojiRegex    *regexp.Regexp
+	ranges        []runeRange // emojiRanges, or only its BMP part with WithBMPOnly, less never
+	bmpOnly       bool
+	never         []rune // Code points excluded with WithNever, sorted
 	allowedEmojis map[string]bool
+	allowedSeqs   map[rune][]string // Multi-rune allowed entries by first rune, longest first
+	whitespace    WhitespacePolicy
+	htmlEntities  bool
+	escapes       bool
+	replacements  map[string]string
+	regexOnly     bool
+	bidiCleanup   bool
+	trace         io.Writer
+	clusters      bool
 }
 
 // NewDetector creates a new emoji detector with predefined emoji patterns.
 func NewDetector() *Detector {
-	emojiPattern := `[\x{1F600}-\x{1F64F}]|[\x{1F300}-\x{1F5FF}]|[\x{1F680}-\x{1F6FF}]|[\x{1F1E0}-\x{1F1FF}]|[\x{2600}-\x{26FF}]|[\x{2700}-\x{27BF}]|[\x{1F900}-\x{1F9FF}]|[\x{1F018}-\x{1F0FF}]|[\x{1F90A}-\x{1F93A}]|[\x{1F940}-\x{1F94C}]|[\x{1F947}-\x{1F978}]|[\x{1F980}-\x{1F991}]|[\x{1F993}-\x{1F9A2}]|[\x{1F9A5}-\x{1F9AA}]|[\x{1F9AE}-\x{1F9CA}]|[\x{1F9CD}-\x{1F9FF}]|[\x{1FA70}-\x{1FA73}]|[\x{1FA78}-\x{1FA7A}]|[\x{1FA80}-\x{1FA82}]|[\x{1FA90}-\x{1FA95}]`
 	return &Detector{
-		emojiRegex:    regexp.MustCompile(emojiPattern),
+		emojiRegex:    compileEmojiRegex(emojiRanges),
+		ranges:        emojiRanges,
 		allowedEmojis: make(map[string]bool),
+		whitespace:    WhitespaceKeep,
 	}
 }
 
-// NewDetectorWithAllowed creates a new emoji detector with allowed emojis that won't be removed.
-func NewDetectorWithAllowed(allowed []string) *Detector {
+// NewDetectorRegexOnly creates an emoji detector whose FindEmojis and Count rely on the
+// regex alone, skipping the per-rune isEmoji pass. Both are built from the same range
+// table, so the results are the same as NewDetector's without the redundant second pass.
+func NewDetectorRegexOnly() *Detector {
 	detector := NewDetector()
+	detector.regexOnly = true
+	return detector
+}
+
+// Clone returns a copy of the Detector that can be configured independently.
+func (d *Detector) Clone() *Detector {
+	clone := *d
+	clone.allowedEmojis = make(map[string]bool, len(d.allowedEmojis))
+	for emoji := range d.allowedEmojis {
+		clone.allowedEmojis[emoji] = true
+	}
+	return &clone
+}
+
+// WithAllowed replaces the emojis that won't be removed and returns the Detector.
+// Entries may be sequences of several code points, such as the flag 🇺🇸 or the
+// family 👨‍👩‍👧; such a sequence is kept whole wherever it appears.
+func (d *Detector) WithAllowed(allowed []string) *Detector {
+	d.allowedEmojis = make(map[string]bool, len(allowed))
 	for _, emoji := range allowed {
-		detector.allowedEmojis[emoji] = true
+		d.allowedEmojis[emoji] = true
 	}
-	return detector
+	d.allowedSeqs = allowedSequences(d.allowedEmojis)
+	return d
+}
+
+// WithReplacements sets per-emoji substitutions used in place of deletion and returns the
+// Detector. Emojis without an entry are still removed, and allowed emojis are left alone.
+// A trailing variation selector (U+FE0F) in a key is ignored, so "❤️" maps ❤.
+func (d *Detector) WithReplacements(replacements map[string]string) *Detector {
+	d.replacements = make(map[string]string, len(replacements))
+	for emoji, replacement := range replacements {
+		d.replacements[strings.TrimSuffix(emoji, string(variationSelector16))] = replacement
+	}
+	return d
+}
+
+// WithWhitespacePolicy sets how spaces adjacent to removed emojis are handled and returns the Detector.
+func (d *Detector) WithWhitespacePolicy(policy WhitespacePolicy) *Detector {
+	d.whitespace = policy
+	return d
+}
+
+// NewDetectorWithAllowed creates a new emoji detector with allowed emojis that won't be removed.
+func NewDetectorWithAllowed(allowed []string) *Detector {
+	return NewDetector().WithAllowed(allowed)
 }
 
 // FindEmojis returns a slice of unique emojis found in the given text (excluding allowed emojis).
 func (d *Detector) FindEmojis(text string) []string {
+	if d.trace != nil {
+		d.traceText(text)
+	}
+
 	var emojis []string
 	seen := make(map[string]bool)
 
-	matches := d.emojiRegex.FindAllString(text, -1)
+	var matches []string
+	switch {
+	case d.clusters:
+		emojis = d.findEmojiClusters(text)
+		for _, cluster := range emojis {
+			seen[cluster] = true
+		}
+	case d.useRegex():
+		matches = d.emojiRegex.FindAllString(text, -1)
+	}
 	for _, match := range matches {
 		// Skip allowed emojis
 		if d.allowedEmojis[match] {
@@ -46,84 +194,378 @@ func (d *Detector) FindEmojis(text string) []string {
 		}
 	}
 
-	for _, r := range text {
-		if isEmoji(r) {
-			emoji := string(r)
-			// Skip allowed emojis
-			if d.allowedEmojis[emoji] {
-				continue
-			}
-			if !seen[emoji] {
-				emojis = append(emojis, emoji)
-				seen[emoji] = true
-			}
+	for i := 0; i < len(text) && d.scanRunes() && !d.clusters; {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		i += size
+		// Skip non-emojis and allowed emojis
+		if !found || d.allowedEmojis[emoji] {
+			continue
+		}
+		if !seen[emoji] {
+			emojis = append(emojis, emoji)
+			seen[emoji] = true
+		}
+	}
+
+	for _, encoded := range d.findEncodedEmojis(text) {
+		if !seen[encoded] {
+			emojis = append(emojis, encoded)
+			seen[encoded] = true
 		}
 	}
 
 	return emojis
 }
 
+// FindEmojiSet returns each unique emoji found in the given text (excluding allowed emojis)
+// mapped to the number of times it occurs.
+func (d *Detector) FindEmojiSet(text string) map[string]int {
+	counts := make(map[string]int)
+
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		i += size
+		// Skip non-emojis and allowed emojis
+		if !found || d.allowedEmojis[emoji] {
+			continue
+		}
+		counts[emoji]++
+	}
+
+	for _, encoded := range d.findEncodedEmojis(text) {
+		counts[encoded]++
+	}
+
+	return counts
+}
+
+// useRegex reports whether FindEmojis and Count can use the regex pass. The regex
+// matches single emojis, so allowed multi-rune sequences turn it off.
+func (d *Detector) useRegex() bool {
+	return len(d.allowedSeqs) == 0
+}
+
+// scanRunes reports whether FindEmojis and Count make the per-rune pass, which
+// NewDetectorRegexOnly skips unless the regex pass is off.
+func (d *Detector) scanRunes() bool {
+	return !d.regexOnly || !d.useRegex()
+}
+
+// Count returns the total number of emoji occurrences in the given text (excluding
+// allowed emojis), counting repeats. An occurrence found by both the regex and the
+// rune pass is counted once, keyed by its starting byte offset.
+func (d *Detector) Count(text string) int {
+	counted := make(map[int]bool)
+
+	if d.useRegex() {
+		for _, loc := range d.emojiRegex.FindAllStringIndex(text, -1) {
+			if !d.allowedEmojis[text[loc[0]:loc[1]]] {
+				counted[loc[0]] = true
+			}
+		}
+	}
+
+	for i := 0; i < len(text) && d.scanRunes(); {
+		size, found := d.nextEmoji(text[i:])
+		if found && !d.allowedEmojis[text[i:i+size]] {
+			counted[i] = true
+		}
+		i += size
+	}
+
+	return len(counted) + len(d.findEncodedEmojis(text))
+}
+
+// EmojiSpans returns the [start, end) byte offsets of every emoji occurrence in the
+// given text (excluding allowed emojis). Spans are ordered and never overlap.
+func (d *Detector) EmojiSpans(text string) [][2]int {
+	var spans [][2]int
+
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		if found && !d.allowedEmojis[text[i:i+size]] {
+			spans = append(spans, [2]int{i, i + size})
+		}
+		i += size
+	}
+
+	return spans
+}
+
+// FindUnsupported returns the unique non-ASCII characters in text that are not detected
+// as emojis, in order of first occurrence. It is a diagnostic aid for spotting symbols
+// that render like emojis but fall outside the detector's ranges, and plays no part in
+// removal. Variation selectors, zero-width joiners and tag characters are not reported.
+func (d *Detector) FindUnsupported(text string) []string {
+	var unsupported []string
+	seen := make(map[rune]bool)
+
+	for _, r := range text {
+		if r < utf8.RuneSelf || d.isEmojiRune(r) || r == variationSelector16 || r == zeroWidthJoiner || isTagRune(r) {
+			continue
+		}
+		if !seen[r] {
+			unsupported = append(unsupported, string(r))
+			seen[r] = true
+		}
+	}
+
+	return unsupported
+}
+
 // RemoveEmojis removes all emojis from the given text (except allowed ones) and returns the cleaned text.
 func (d *Detector) RemoveEmojis(text string) string {
-	// If we have allowed emojis, we need to be more selective
-	if len(d.allowedEmojis) > 0 {
-		// Process character by character to preserve allowed emojis
-		var cleaned []rune
-		textRunes := []rune(text)
-
-		for i := 0; i < len(textRunes); i++ {
-			r := textRunes[i]
-			emoji := string(r)
-
-			// Check if this rune is an emoji
-			if isEmoji(r) || d.emojiRegex.MatchString(emoji) {
-				// Keep it if it's allowed
-				if d.allowedEmojis[emoji] {
-					cleaned = append(cleaned, r)
-				}
-				// Otherwise skip it (remove it)
-			} else {
-				// Not an emoji, keep it
-				cleaned = append(cleaned, r)
-			}
+	text = d.removeEncodedEmojis(text)
+	if d.bidiCleanup {
+		text = d.removeEmptiedBidiRuns(text)
+	}
+
+	if d.whitespace != "" && d.whitespace != WhitespaceKeep {
+		return d.removeEmojisCollapsingSpaces(text)
+	}
+
+	// Single linear pass: each rune is checked against the range table and the
+	// allow list. Bytes that are not removed, including invalid UTF-8, are copied
+	// through unchanged.
+	var cleaned strings.Builder
+	cleaned.Grow(len(text))
+
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		if !found || d.allowedEmojis[emoji] {
+			cleaned.WriteString(emoji)
+		} else if replacement, ok := d.replacements[emoji]; ok {
+			cleaned.WriteString(replacement)
 		}
-		return string(cleaned)
+		i += size
+	}
+
+	return cleaned.String()
+}
+
+// StripAndReport removes emojis from text and returns the cleaned text along with the
+// unique emojis that were removed, in first-seen order. The result matches calling
+// FindEmojis and then RemoveEmojis, but literal emojis are found and removed in a single
+// pass. Whitespace collapsing, bidi cleanup and grapheme cluster reporting need their
+// own passes, so with any of them enabled this falls back to the two separate calls.
+func (d *Detector) StripAndReport(text string) (string, []string) {
+	if d.needsSeparatePasses() {
+		return d.RemoveEmojis(text), d.FindEmojis(text)
 	}
+	cleaned, emojis, _ := d.stripInOnePass(text)
+	return cleaned, emojis
+}
 
-	// No allowed emojis, use the faster method
-	result := d.emojiRegex.ReplaceAllString(text, "")
+// RemoveEmojisWithStats removes emojis from text like RemoveEmojis and also returns how
+// many unique emojis were removed and how many occurrences in total, repeats included.
+// The counts match len(FindEmojis(text)) and Count(text), and like StripAndReport the
+// text is scanned once unless a setting needs its own pass.
+func (d *Detector) RemoveEmojisWithStats(text string) (cleaned string, uniqueCount int, totalCount int) {
+	if d.needsSeparatePasses() {
+		return d.RemoveEmojis(text), len(d.FindEmojis(text)), d.Count(text)
+	}
+	cleaned, emojis, total := d.stripInOnePass(text)
+	return cleaned, len(emojis), total
+}
 
-	var cleaned []rune
-	for _, r := range result {
-		if !isEmoji(r) {
-			cleaned = append(cleaned, r)
+// needsSeparatePasses reports whether a setting keeps removal and reporting from
+// sharing one pass over the text.
+func (d *Detector) needsSeparatePasses() bool {
+	return d.bidiCleanup || d.clusters || (d.whitespace != "" && d.whitespace != WhitespaceKeep)
+}
+
+// stripInOnePass removes literal and encoded emojis from text and returns the cleaned
+// text, the unique emojis removed in first-seen order and the number of occurrences.
+func (d *Detector) stripInOnePass(text string) (string, []string, int) {
+	if d.trace != nil {
+		d.traceText(text)
+	}
+
+	encoded := d.findEncodedEmojis(text)
+	text = d.removeEncodedEmojis(text)
+
+	var emojis []string
+	seen := make(map[string]bool)
+	total := len(encoded)
+
+	var cleaned strings.Builder
+	cleaned.Grow(len(text))
+
+	for i := 0; i < len(text); {
+		size, found := d.nextEmoji(text[i:])
+		emoji := text[i : i+size]
+		i += size
+		if !found || d.allowedEmojis[emoji] {
+			cleaned.WriteString(emoji)
+			continue
+		}
+		total++
+		if replacement, ok := d.replacements[emoji]; ok {
+			cleaned.WriteString(replacement)
+		}
+		if !seen[emoji] {
+			emojis = append(emojis, emoji)
+			seen[emoji] = true
+		}
+	}
+
+	for _, e := range encoded {
+		if !seen[e] {
+			emojis = append(emojis, e)
+			seen[e] = true
 		}
 	}
 
-	return string(cleaned)
+	return cleaned.String(), emojis, total
 }
 
-func isEmoji(r rune) bool {
-	if r >= 0x1F600 && r <= 0x1F64F {
-		return true
+// RemoveEmojisExcept removes all emojis from the given text except those in keep, for
+// this call only. The keep set is used in place of the Detector's allow list, which is
+// neither consulted nor changed; all other settings still apply.
+func (d *Detector) RemoveEmojisExcept(text string, keep map[string]bool) string {
+	scoped := *d
+	scoped.allowedEmojis = keep
+	scoped.allowedSeqs = allowedSequences(keep)
+	return scoped.RemoveEmojis(text)
+}
+
+// removeEmojisCollapsingSpaces removes emojis and drops adjacent spaces according to the
+// whitespace policy. Neighbors are judged in the original text, and a neighbor that is
+// itself being removed does not count as a space.
+func (d *Detector) removeEmojisCollapsingSpaces(text string) string {
+	runes := []rune(text)
+	remove := make([]bool, len(runes))
+	replaced := make(map[int]string) // Replacement text keyed by the rune index it stands in for
+	var deleted [][2]int             // Rune spans of deleted emojis, including any tag sequence
+	for i := 0; i < len(runes); {
+		end := i + 1
+		if !d.isEmojiRune(runes[i]) {
+			i = end
+			continue
+		}
+		if n := d.allowedSequenceRunes(runes[i:]); n > 0 {
+			i += n
+			continue
+		}
+		for end < len(runes) && isTagRune(runes[end]) {
+			end++
+			if runes[end-1] == cancelTagRune {
+				break
+			}
+		}
+
+		emoji := string(runes[i:end])
+		if !d.allowedEmojis[emoji] {
+			for j := i; j < end; j++ {
+				remove[j] = true
+			}
+			// Replaced emojis stay in place, so only deleted ones collapse spaces
+			if replacement, ok := d.replacements[emoji]; ok {
+				replaced[i] = replacement
+			} else {
+				deleted = append(deleted, [2]int{i, end})
+			}
+		}
+		i = end
 	}
-	if r >= 0x1F300 && r <= 0x1F5FF {
-		return true
+
+	isSpace := func(i int) bool {
+		return i >= 0 && i < len(runes) && runes[i] == ' ' && !remove[i]
 	}
-	if r >= 0x1F680 && r <= 0x1F6FF {
-		return true
+
+	if d.whitespace == WhitespaceCollapseBetween {
+		collapseBetween(runes, remove, deleted)
 	}
-	if r >= 0x1F1E0 && r <= 0x1F1FF {
-		return true
+
+	for _, span := range deleted {
+		before, after := span[0]-1, span[1]
+		switch d.whitespace {
+		case WhitespaceCollapseLeading:
+			if isSpace(before) {
+				remove[before] = true
+			}
+		case WhitespaceCollapseTrailing:
+			if isSpace(after) {
+				remove[after] = true
+			}
+		case WhitespaceCollapseBoth:
+			if isSpace(after) {
+				remove[after] = true
+			} else if isSpace(before) {
+				remove[before] = true
+			}
+		}
 	}
-	if r >= 0x2600 && r <= 0x26FF {
-		return true
+
+	var cleaned strings.Builder
+	cleaned.Grow(len(text))
+	for i, r := range runes {
+		if replacement, ok := replaced[i]; ok {
+			cleaned.WriteString(replacement)
+		} else if !remove[i] {
+			cleaned.WriteRune(r)
+		}
 	}
-	if r >= 0x2700 && r <= 0x27BF {
-		return true
+	return cleaned.String()
+}
+
+// collapseBetween marks for removal the single spaces separating consecutive deleted
+// spans, and the space after each run of two or more such spans when the run also has a
+// space before it, leaving one space where the run stood.
+func collapseBetween(runes []rune, remove []bool, deleted [][2]int) {
+	isSpace := func(i int) bool {
+		return i >= 0 && i < len(runes) && runes[i] == ' ' && !remove[i]
+	}
+
+	for first := 0; first < len(deleted); {
+		last := first
+		for last+1 < len(deleted) && deleted[last+1][0] == deleted[last][1]+1 && runes[deleted[last][1]] == ' ' {
+			remove[deleted[last][1]] = true
+			last++
+		}
+		if last > first && isSpace(deleted[first][0]-1) && isSpace(deleted[last][1]) {
+			remove[deleted[last][1]] = true
+		}
+		first = last + 1
 	}
-	if r >= 0x1F900 && r <= 0x1F9FF {
-		return true
+}
+
+// findEncodedEmojis returns the emojis written as HTML entities or string escapes in
+// text, for the encodings that are enabled.
+func (d *Detector) findEncodedEmojis(text string) []string {
+	return append(d.findEmojiEntities(text), d.findEmojiEscapes(text)...)
+}
+
+// removeEncodedEmojis strips emojis written as HTML entities or string escapes from
+// text, for the encodings that are enabled.
+func (d *Detector) removeEncodedEmojis(text string) string {
+	return d.removeEmojiEscapes(d.removeEmojiEntities(text))
+}
+
+// isEmojiRune reports whether a single rune falls in the Detector's emoji ranges.
+func (d *Detector) isEmojiRune(r rune) bool {
+	return inRanges(d.ranges, r)
+}
+
+func isEmoji(r rune) bool {
+	return inRanges(emojiRanges, r)
+}
+
+// inRanges reports whether r falls in ranges, a sorted subset of emojiRanges.
+func inRanges(ranges []runeRange, r rune) bool {
+	if r < minEmojiRune {
+		return false
+	}
+	for _, rr := range ranges {
+		if r < rr.lo {
+			return false // Ranges are sorted, so no later range can match
+		}
+		if r <= rr.hi {
+			return true
+		}
 	}
 	return false
 }
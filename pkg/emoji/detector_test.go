@@ -3,6 +3,7 @@ package emoji
 import (
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -104,6 +105,106 @@ func TestDetector_FindEmojis(t *testing.T) {
 	}
 }
 
+func TestDetector_FindEmojiSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		input    string
+		expected map[string]int
+	}{
+		{
+			name:     "no emojis",
+			input:    "Hello world",
+			expected: map[string]int{},
+		},
+		{
+			name:     "repeated emojis are counted",
+			input:    "😊 one 😊 two 😊 three 🚀",
+			expected: map[string]int{"😊": 3, "🚀": 1},
+		},
+		{
+			name:     "adjacent emojis",
+			input:    "🚀🚀✨",
+			expected: map[string]int{"🚀": 2, "✨": 1},
+		},
+		{
+			name:     "allowed emojis are not counted",
+			allowed:  []string{"✅"},
+			input:    "✅ done ✅ done ❌ failed",
+			expected: map[string]int{"❌": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetectorWithAllowed(tt.allowed)
+			result := detector.FindEmojiSet(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("FindEmojiSet(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetector_EmojiSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		input    string
+		expected [][2]int
+	}{
+		{
+			name:     "no emojis",
+			input:    "Hello world",
+			expected: nil,
+		},
+		{
+			name:     "single emoji",
+			input:    "Hi 😊!",
+			expected: [][2]int{{3, 7}},
+		},
+		{
+			name:     "adjacent emojis are separate spans",
+			input:    "🚀✨",
+			expected: [][2]int{{0, 4}, {4, 7}},
+		},
+		{
+			name:     "multibyte text before emoji",
+			input:    "Café 🎉",
+			expected: [][2]int{{6, 10}},
+		},
+		{
+			name:     "allowed emojis are skipped",
+			allowed:  []string{"✅"},
+			input:    "✅ ❌",
+			expected: [][2]int{{4, 7}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetectorWithAllowed(tt.allowed)
+			spans := detector.EmojiSpans(tt.input)
+			if !reflect.DeepEqual(spans, tt.expected) {
+				t.Errorf("EmojiSpans(%q) = %v, want %v", tt.input, spans, tt.expected)
+			}
+
+			prevEnd := 0
+			for _, span := range spans {
+				if span[0] < prevEnd {
+					t.Errorf("Span %v overlaps or is out of order", span)
+				}
+				prevEnd = span[1]
+
+				slice := tt.input[span[0]:span[1]]
+				if found := detector.FindEmojis(slice); len(found) != 1 || found[0] != slice {
+					t.Errorf("Span %v covers %q, not exactly one emoji", span, slice)
+				}
+			}
+		})
+	}
+}
+
 func TestDetector_RemoveEmojis(t *testing.T) {
 	detector := NewDetector()
 
@@ -179,6 +280,175 @@ func TestDetector_RemoveEmojis(t *testing.T) {
 	}
 }
 
+func TestDetector_WhitespacePolicy(t *testing.T) {
+	inputs := []string{"Hello 😊 world", "😊 Hello", "Hello 😊", "a 😊 🚀 b", "tab\t😊\tkept"}
+
+	tests := []struct {
+		policy   WhitespacePolicy
+		expected []string
+	}{
+		{WhitespaceKeep, []string{"Hello  world", " Hello", "Hello ", "a   b", "tab\t\tkept"}},
+		{WhitespaceCollapseLeading, []string{"Hello world", " Hello", "Hello", "a b", "tab\t\tkept"}},
+		{WhitespaceCollapseTrailing, []string{"Hello world", "Hello", "Hello ", "a b", "tab\t\tkept"}},
+		{WhitespaceCollapseBoth, []string{"Hello world", "Hello", "Hello", "a b", "tab\t\tkept"}},
+		{WhitespaceCollapseBetween, []string{"Hello  world", " Hello", "Hello ", "a b", "tab\t\tkept"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			detector := NewDetector().WithWhitespacePolicy(tt.policy)
+			for i, input := range inputs {
+				result := detector.RemoveEmojis(input)
+				if result != tt.expected[i] {
+					t.Errorf("RemoveEmojis(%q) = %q, want %q", input, result, tt.expected[i])
+				}
+			}
+		})
+	}
+
+	t.Run("allowed emojis keep their spaces", func(t *testing.T) {
+		detector := NewDetectorWithAllowed([]string{"✅"}).WithWhitespacePolicy(WhitespaceCollapseBoth)
+		if result := detector.RemoveEmojis("ok ✅ done ❌ x"); result != "ok ✅ done x" {
+			t.Errorf("RemoveEmojis() = %q, want %q", result, "ok ✅ done x")
+		}
+	})
+
+	t.Run("collapse-between only merges spaces between removed emojis", func(t *testing.T) {
+		detector := NewDetector().WithWhitespacePolicy(WhitespaceCollapseBetween)
+		cases := map[string]string{
+			"Hi 😊 🚀 🎉 there": "Hi there", // adjacent emojis collapse to one space
+			"a 😊 b 🚀 c":      "a  b  c",  // emojis separated by words keep their spaces
+			"a 😊  🚀 b":       "a    b",   // only single separating spaces collapse
+			"😊 🚀 start":      " start",   // no leading space to merge with
+			"end 😊 🚀":        "end ",     // no trailing space to merge with
+			"x😊 🚀y":          "xy",       // a run without outer spaces
+		}
+		for input, want := range cases {
+			if got := detector.RemoveEmojis(input); got != want {
+				t.Errorf("RemoveEmojis(%q) = %q, want %q", input, got, want)
+			}
+		}
+
+		// An allowed emoji breaks the run, so its neighbors keep their spaces
+		allowing := NewDetectorWithAllowed([]string{"✅"}).WithWhitespacePolicy(WhitespaceCollapseBetween)
+		if got := allowing.RemoveEmojis("ok ✅ ❌ x"); got != "ok ✅  x" {
+			t.Errorf("RemoveEmojis() = %q, want %q", got, "ok ✅  x")
+		}
+	})
+
+	t.Run("invalid policy name", func(t *testing.T) {
+		if _, err := ParseWhitespacePolicy("squash"); err == nil {
+			t.Error("Expected error for invalid policy")
+		}
+	})
+}
+
+func TestDetector_WithReplacements(t *testing.T) {
+	replacements := map[string]string{"🚀": "[rocket]", "✅": "[done]", "❤️": "<3"}
+
+	tests := []struct {
+		name     string
+		detector *Detector
+		input    string
+		expected string
+	}{
+		{
+			name:     "mapped and unmapped emojis",
+			detector: NewDetector().WithReplacements(replacements),
+			input:    "✅ ship 🚀 party 🎉",
+			expected: "[done] ship [rocket] party ",
+		},
+		{
+			name:     "variation selector in key",
+			detector: NewDetector().WithReplacements(replacements),
+			input:    "I ❤ Go",
+			expected: "I <3 Go",
+		},
+		{
+			name:     "allowed emoji is not replaced",
+			detector: NewDetectorWithAllowed([]string{"✅"}).WithReplacements(replacements),
+			input:    "✅ 🚀",
+			expected: "✅ [rocket]",
+		},
+		{
+			name:     "replaced emojis keep their spaces under a whitespace policy",
+			detector: NewDetector().WithReplacements(replacements).WithWhitespacePolicy(WhitespaceCollapseBoth),
+			input:    "go 🚀 now 🎉 done",
+			expected: "go [rocket] now done",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.detector.RemoveEmojis(tt.input); result != tt.expected {
+				t.Errorf("RemoveEmojis(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetector_Count(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector *Detector
+		input    string
+		expected int
+	}{
+		{"no emojis", NewDetector(), "plain text", 0},
+		{"single emoji", NewDetector(), "Hello 😊", 1},
+		{"repeated emoji", NewDetector(), "🚀🚀 go 🚀", 3},
+		{"mixed emojis", NewDetector(), "✅ done 🎉 party 🎉 ✅ 🚀", 5},
+		{"allowed emojis not counted", NewDetectorWithAllowed([]string{"✅"}), "✅ 🚀 ✅ 🚀", 2},
+		{"html entities", NewDetector().WithHTMLEntities(true), "🚀 &#x1F600; &#128512;", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.detector.Count(tt.input); result != tt.expected {
+				t.Errorf("Count(%q) = %d, want %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetector_FindUnsupported(t *testing.T) {
+	detector := NewDetector()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"ascii only", "plain text", nil},
+		{"detected emoji not reported", "Hello 😊 ❤️", nil},
+		{"chinese characters", "你好 😊 世界 你好", []string{"你", "好", "世", "界"}},
+		{"private use glyph", "icon \uE000 here 🚀", []string{"\uE000"}},
+		{"symbol outside the ranges", "star ⭐ arrow ↩", []string{"⭐", "↩"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detector.FindUnsupported(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("FindUnsupported(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetector_ChessSymbols(t *testing.T) {
+	detector := NewDetector()
+	text := "Opening \U0001FA00 vs \U0001FA60 move"
+
+	found := detector.FindEmojis(text)
+	if expected := []string{"\U0001FA00", "\U0001FA60"}; !reflect.DeepEqual(found, expected) {
+		t.Errorf("FindEmojis() = %q, want %q", found, expected)
+	}
+	if cleaned := detector.RemoveEmojis(text); cleaned != "Opening  vs  move" {
+		t.Errorf("RemoveEmojis() = %q", cleaned)
+	}
+}
+
 func TestIsEmoji(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -192,6 +462,8 @@ func TestIsEmoji(t *testing.T) {
 		{"checkmark", '✅', true},
 		{"lightning", '⚡', true},
 		{"target", '🎯', true},
+		{"neutral chess king", '🨀', true},
+		{"xiangqi red general", '🩠', true},
 
 		// Non-emoji characters
 		{"letter a", 'a', false},
@@ -283,3 +555,376 @@ func TestDetector_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+// legacyRemoveEmojis is the previous RemoveEmojis implementation, which matched the
+// regex once per rune when an allow list was set. It is kept to check that the
+// single-pass implementation produces identical output and to benchmark against.
+func legacyRemoveEmojis(d *Detector, text string) string {
+	if len(d.allowedEmojis) > 0 {
+		var cleaned []rune
+		for _, r := range []rune(text) {
+			emoji := string(r)
+			if isEmoji(r) || d.emojiRegex.MatchString(emoji) {
+				if d.allowedEmojis[emoji] {
+					cleaned = append(cleaned, r)
+				}
+			} else {
+				cleaned = append(cleaned, r)
+			}
+		}
+		return string(cleaned)
+	}
+
+	result := d.emojiRegex.ReplaceAllString(text, "")
+	var cleaned []rune
+	for _, r := range result {
+		if !isEmoji(r) {
+			cleaned = append(cleaned, r)
+		}
+	}
+	return string(cleaned)
+}
+
+func TestDetector_RemoveEmojisMatchesLegacy(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain text only",
+		"Hello 😊 world 🌍 test 🚀",
+		"✅ done ✅ done ❌ failed 🎉",
+		"Café 😊 résumé 🚀 naïve 中文",
+		"🀄 mahjong 🂡 cards 🥇 medal 🦄 unicorn 🩰 ballet",
+		"😊🌍🚀✨🎉💯",
+		"Line 1 😊\nLine 2 🚀\r\nLine 3",
+	}
+
+	detectors := map[string]*Detector{
+		"no allow list":   NewDetector(),
+		"with allow list": NewDetectorWithAllowed([]string{"✅", "🚀"}),
+	}
+
+	for name, detector := range detectors {
+		t.Run(name, func(t *testing.T) {
+			for _, input := range inputs {
+				if got, want := detector.RemoveEmojis(input), legacyRemoveEmojis(detector, input); got != want {
+					t.Errorf("RemoveEmojis(%q) = %q, legacy = %q", input, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid UTF-8 is preserved", func(t *testing.T) {
+		input := "bad \xff byte 😊"
+		if got := NewDetector().RemoveEmojis(input); got != "bad \xff byte " {
+			t.Errorf("RemoveEmojis(%q) = %q", input, got)
+		}
+	})
+}
+
+func TestEmojiRanges_Canonical(t *testing.T) {
+	if emojiRanges[0].lo != minEmojiRune {
+		t.Errorf("minEmojiRune = %X, want %X", minEmojiRune, emojiRanges[0].lo)
+	}
+
+	for i, rr := range emojiRanges {
+		if rr.lo > rr.hi {
+			t.Errorf("Range %d is inverted: %X-%X", i, rr.lo, rr.hi)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := emojiRanges[i-1]
+		if rr.lo <= prev.hi {
+			t.Errorf("Range %X-%X overlaps or is out of order after %X-%X", rr.lo, rr.hi, prev.lo, prev.hi)
+		}
+		if rr.lo == prev.hi+1 {
+			t.Errorf("Range %X-%X is adjacent to %X-%X and should be merged", rr.lo, rr.hi, prev.lo, prev.hi)
+		}
+	}
+}
+
+func TestDetector_EnclosedAlphanumericSupplement(t *testing.T) {
+	detector := NewDetector()
+	for _, emoji := range []string{"🅰", "🆎", "🆓", "🆒"} {
+		text := "blood type " + emoji + " here"
+		if found := detector.FindEmojis(text); !reflect.DeepEqual(found, []string{emoji}) {
+			t.Errorf("FindEmojis(%q) = %q, want [%s]", text, found, emoji)
+		}
+		if got := detector.RemoveEmojis(text); got != "blood type  here" {
+			t.Errorf("RemoveEmojis(%q) = %q", text, got)
+		}
+	}
+
+	// Enclosed letters that are not emojis, such as the parenthesized and circled ones, stay
+	if got := detector.RemoveEmojis("🄐 🄫"); got != "🄐 🄫" {
+		t.Errorf("RemoveEmojis() removed non-emoji enclosed letters: %q", got)
+	}
+}
+
+func TestDetector_SupplementaryPlaneConsistency(t *testing.T) {
+	// Canonical emoji blocks within U+1F000-U+1FAFF, written out independently of emojiRanges
+	canonical := []runeRange{
+		{0x1F018, 0x1F0FF},
+		{0x1F170, 0x1F171},
+		{0x1F17E, 0x1F17F},
+		{0x1F18E, 0x1F18E},
+		{0x1F191, 0x1F19A},
+		{0x1F1E0, 0x1F1FF},
+		{0x1F300, 0x1F64F},
+		{0x1F680, 0x1F6FF},
+		{0x1F900, 0x1F9FF},
+		{0x1FA00, 0x1FA6F},
+		{0x1FA70, 0x1FA73},
+		{0x1FA78, 0x1FA7A},
+		{0x1FA80, 0x1FA82},
+		{0x1FA90, 0x1FA95},
+	}
+	inCanonical := func(r rune) bool {
+		for _, rr := range canonical {
+			if r >= rr.lo && r <= rr.hi {
+				return true
+			}
+		}
+		return false
+	}
+
+	detector := NewDetector()
+	for r := rune(0x1F000); r <= 0x1FAFF; r++ {
+		text := "a" + string(r) + "b"
+		want := inCanonical(r)
+
+		if got := isEmoji(r); got != want {
+			t.Errorf("isEmoji(U+%X) = %v, want %v", r, got, want)
+		}
+		if got := detector.emojiRegex.MatchString(string(r)); got != want {
+			t.Errorf("regex match for U+%X = %v, want %v", r, got, want)
+		}
+		if got := len(detector.FindEmojis(text)) == 1; got != want {
+			t.Errorf("FindEmojis found U+%X = %v, want %v", r, got, want)
+		}
+		if got := detector.RemoveEmojis(text) == "ab"; got != want {
+			t.Errorf("RemoveEmojis removed U+%X = %v, want %v", r, got, want)
+		}
+	}
+}
+
+func TestEmojiPattern(t *testing.T) {
+	pattern := emojiPattern([]runeRange{{0x2600, 0x26FF}, {0x1F600, 0x1F64F}})
+	expected := `[\x{2600}-\x{26FF}]|[\x{1F600}-\x{1F64F}]`
+	if pattern != expected {
+		t.Errorf("emojiPattern() = %q, want %q", pattern, expected)
+	}
+}
+
+// benchmarkText is a mix of prose and emojis representative of a source file.
+var benchmarkText = strings.Repeat("func main() { fmt.Println(\"Hello 😊 world 🚀\") } // ✅ done ❌ todo\n", 500)
+
+func BenchmarkRemoveEmojis(b *testing.B) {
+	detector := NewDetector()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.RemoveEmojis(benchmarkText)
+	}
+}
+
+func BenchmarkRemoveEmojisLegacy(b *testing.B) {
+	detector := NewDetector()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		legacyRemoveEmojis(detector, benchmarkText)
+	}
+}
+
+func BenchmarkRemoveEmojisWithAllowList(b *testing.B) {
+	detector := NewDetectorWithAllowed([]string{"✅"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.RemoveEmojis(benchmarkText)
+	}
+}
+
+func BenchmarkRemoveEmojisWithAllowListLegacy(b *testing.B) {
+	detector := NewDetectorWithAllowed([]string{"✅"})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		legacyRemoveEmojis(detector, benchmarkText)
+	}
+}
+
+func BenchmarkFindEmojis(b *testing.B) {
+	detector := NewDetector()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.FindEmojis(benchmarkText)
+	}
+}
+
+func BenchmarkFindEmojisRegexOnly(b *testing.B) {
+	detector := NewDetectorRegexOnly()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.FindEmojis(benchmarkText)
+	}
+}
+
+func TestNewDetectorRegexOnly_MatchesDualPass(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain text",
+		benchmarkText,
+		"Hello 😊 World 🚀 and 😊 again",
+		"Love ❤️ family 👨‍👩‍👧 flag 🇺🇸 thumbs 👍🏽",
+		"Scotland 🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F done",
+		"Chess \U0001FA00 and extended \U0001FA70",
+		"你好 😊 世界 ⭐ ↩",
+	}
+
+	for _, allowed := range [][]string{nil, {"😊", "✅"}} {
+		dual := NewDetectorWithAllowed(allowed)
+		regexOnly := NewDetectorRegexOnly().WithAllowed(allowed)
+		for _, input := range inputs {
+			if got, want := regexOnly.FindEmojis(input), dual.FindEmojis(input); !reflect.DeepEqual(got, want) {
+				t.Errorf("FindEmojis(%q) with allowed %q = %q, want %q", input, allowed, got, want)
+			}
+			if got, want := regexOnly.Count(input), dual.Count(input); got != want {
+				t.Errorf("Count(%q) with allowed %q = %d, want %d", input, allowed, got, want)
+			}
+		}
+	}
+}
+
+func TestDetector_RemoveEmojisExcept(t *testing.T) {
+	detector := NewDetectorWithAllowed([]string{"✅"})
+
+	tests := []struct {
+		name     string
+		keep     map[string]bool
+		input    string
+		expected string
+	}{
+		{"keep set replaces allow list", map[string]bool{"🚀": true}, "✅ 🚀 🎉", " 🚀 "},
+		{"keep set with several emojis", map[string]bool{"🚀": true, "🎉": true}, "✅ 🚀 🎉 ✨", " 🚀 🎉 "},
+		{"nil keep set removes everything", nil, "✅ 🚀", " "},
+		{"keep set including the allowed emoji", map[string]bool{"✅": true}, "✅ 🚀", "✅ "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := detector.RemoveEmojisExcept(tt.input, tt.keep); result != tt.expected {
+				t.Errorf("RemoveEmojisExcept(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	if result := detector.RemoveEmojis("✅ 🚀"); result != "✅ " {
+		t.Errorf("Expected the configured allow list to be unchanged, got %q", result)
+	}
+
+	collapsing := NewDetector().WithWhitespacePolicy(WhitespaceCollapseBoth)
+	if result := collapsing.RemoveEmojisExcept("go 🚀 now 🎉 done", map[string]bool{"🎉": true}); result != "go now 🎉 done" {
+		t.Errorf("Expected other settings to apply, got %q", result)
+	}
+}
+
+func TestDetector_CloneWithAllowed(t *testing.T) {
+	original := NewDetectorWithAllowed([]string{"✅"}).WithReplacements(map[string]string{"🚀": "[rocket]"})
+	clone := original.Clone().WithAllowed([]string{"🎉"})
+
+	if result := original.RemoveEmojis("✅ 🚀 🎉"); result != "✅ [rocket] " {
+		t.Errorf("original RemoveEmojis() = %q, want the original allow list", result)
+	}
+	if result := clone.RemoveEmojis("✅ 🚀 🎉"); result != " [rocket] 🎉" {
+		t.Errorf("clone RemoveEmojis() = %q, want the replaced allow list and inherited replacements", result)
+	}
+}
+
+func TestDetector_StripAndReport(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain text",
+		benchmarkText,
+		"Hello 😊 World 🚀 and 😊 again",
+		"Love ❤️ family 👨‍👩‍👧 flag 🇺🇸 thumbs 👍🏽",
+		"Scotland 🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F done",
+		"entity &#128512; escape \\U0001F600 and 😀",
+		"word 😊 word\nonly 🎉 here",
+		"invalid \xff utf8 🚀",
+	}
+
+	detectors := map[string]*Detector{
+		"default":      NewDetector(),
+		"allowed":      NewDetectorWithAllowed([]string{"😊", "✅"}),
+		"replacements": NewDetector().WithReplacements(map[string]string{"🚀": "rocket"}),
+		"encoded":      NewDetector().WithHTMLEntities(true).WithEscapes(true),
+		"whitespace":   NewDetector().WithWhitespacePolicy(WhitespaceCollapseBoth),
+		"bidi":         NewDetector().WithBidiCleanup(true),
+	}
+
+	for name, d := range detectors {
+		for _, input := range inputs {
+			cleaned, found := d.StripAndReport(input)
+			if want := d.RemoveEmojis(input); cleaned != want {
+				t.Errorf("%s: StripAndReport(%q) cleaned = %q, want %q", name, input, cleaned, want)
+			}
+			if want := d.FindEmojis(input); !reflect.DeepEqual(found, want) {
+				t.Errorf("%s: StripAndReport(%q) found = %q, want %q", name, input, found, want)
+			}
+		}
+	}
+}
+
+func TestDetector_RemoveEmojisWithStats(t *testing.T) {
+	d := NewDetector()
+	cleaned, unique, total := d.RemoveEmojisWithStats("Hello 😊 World 🚀 and 😊 😊 again")
+	if cleaned != "Hello  World  and   again" {
+		t.Errorf("cleaned = %q", cleaned)
+	}
+	if unique != 2 || total != 4 {
+		t.Errorf("unique, total = %d, %d, want 2, 4", unique, total)
+	}
+
+	inputs := []string{
+		"",
+		"plain text",
+		benchmarkText,
+		"Love ❤️ family 👨‍👩‍👧 flag 🇺🇸",
+		"entity &#128512; escape \\U0001F600 and 😀 😀",
+	}
+	detectors := map[string]*Detector{
+		"default":    NewDetector(),
+		"allowed":    NewDetectorWithAllowed([]string{"😊"}),
+		"encoded":    NewDetector().WithHTMLEntities(true).WithEscapes(true),
+		"whitespace": NewDetector().WithWhitespacePolicy(WhitespaceCollapseBoth),
+		"clusters":   NewDetector().WithGraphemeClusters(true),
+	}
+	for name, d := range detectors {
+		for _, input := range inputs {
+			cleaned, unique, total := d.RemoveEmojisWithStats(input)
+			if want := d.RemoveEmojis(input); cleaned != want {
+				t.Errorf("%s: RemoveEmojisWithStats(%q) cleaned = %q, want %q", name, input, cleaned, want)
+			}
+			if want := len(d.FindEmojis(input)); unique != want {
+				t.Errorf("%s: RemoveEmojisWithStats(%q) unique = %d, want %d", name, input, unique, want)
+			}
+			if want := d.Count(input); total != want {
+				t.Errorf("%s: RemoveEmojisWithStats(%q) total = %d, want %d", name, input, total, want)
+			}
+		}
+	}
+}
+
+func BenchmarkStripAndReport(b *testing.B) {
+	detector := NewDetector()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.StripAndReport(benchmarkText)
+	}
+}
+
+func BenchmarkFindThenRemoveEmojis(b *testing.B) {
+	detector := NewDetector()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		detector.FindEmojis(benchmarkText)
+		detector.RemoveEmojis(benchmarkText)
+	}
+}
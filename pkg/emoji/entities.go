@@ -0,0 +1,68 @@
+package emoji
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// htmlEntityRegex matches hexadecimal and decimal HTML numeric character references.
+var htmlEntityRegex = regexp.MustCompile(`&#(?:[xX]([0-9a-fA-F]{1,6})|([0-9]{1,7}));`)
+
+// WithHTMLEntities enables detection and removal of emojis written as HTML numeric
+// entities such as &#x1F600; or &#128512;, and returns the Detector. Entities that
+// decode to non-emoji characters, and named entities like &amp;, are left alone.
+func (d *Detector) WithHTMLEntities(enabled bool) *Detector {
+	d.htmlEntities = enabled
+	return d
+}
+
+// isEmojiEntity reports whether an entity decodes to an emoji that is not allowed.
+func (d *Detector) isEmojiEntity(entity string) bool {
+	groups := htmlEntityRegex.FindStringSubmatch(entity)
+	if groups == nil {
+		return false
+	}
+
+	var code int64
+	var err error
+	if groups[1] != "" {
+		code, err = strconv.ParseInt(groups[1], 16, 32)
+	} else {
+		code, err = strconv.ParseInt(groups[2], 10, 32)
+	}
+	if err != nil {
+		return false
+	}
+
+	r := rune(code)
+	return d.isEmojiRune(r) && !d.allowedEmojis[string(r)]
+}
+
+// findEmojiEntities returns every emoji entity in text, in order of occurrence.
+func (d *Detector) findEmojiEntities(text string) []string {
+	if !d.htmlEntities {
+		return nil
+	}
+
+	var entities []string
+	for _, entity := range htmlEntityRegex.FindAllString(text, -1) {
+		if d.isEmojiEntity(entity) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// removeEmojiEntities strips emoji entities from text.
+func (d *Detector) removeEmojiEntities(text string) string {
+	if !d.htmlEntities {
+		return text
+	}
+
+	return htmlEntityRegex.ReplaceAllStringFunc(text, func(entity string) string {
+		if d.isEmojiEntity(entity) {
+			return ""
+		}
+		return entity
+	})
+}
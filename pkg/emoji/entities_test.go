@@ -0,0 +1,59 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector_HTMLEntities(t *testing.T) {
+	input := "Smile &#x1F600; and &#128512; but keep &amp; &#65; &lt; &#x2014;"
+
+	t.Run("disabled by default", func(t *testing.T) {
+		detector := NewDetector()
+		if emojis := detector.FindEmojis(input); len(emojis) != 0 {
+			t.Errorf("FindEmojis() = %v, want none", emojis)
+		}
+		if result := detector.RemoveEmojis(input); result != input {
+			t.Errorf("RemoveEmojis() changed input: %q", result)
+		}
+	})
+
+	t.Run("hex and decimal entities detected", func(t *testing.T) {
+		detector := NewDetector().WithHTMLEntities(true)
+		expected := []string{"&#x1F600;", "&#128512;"}
+		if emojis := detector.FindEmojis(input); !reflect.DeepEqual(emojis, expected) {
+			t.Errorf("FindEmojis() = %v, want %v", emojis, expected)
+		}
+
+		counts := detector.FindEmojiSet("&#x1F680; &#x1f680; &#128640;")
+		if !reflect.DeepEqual(counts, map[string]int{"&#x1F680;": 1, "&#x1f680;": 1, "&#128640;": 1}) {
+			t.Errorf("FindEmojiSet() = %v", counts)
+		}
+	})
+
+	t.Run("only emoji entities removed", func(t *testing.T) {
+		detector := NewDetector().WithHTMLEntities(true)
+		expected := "Smile  and  but keep &amp; &#65; &lt; &#x2014;"
+		if result := detector.RemoveEmojis(input); result != expected {
+			t.Errorf("RemoveEmojis() = %q, want %q", result, expected)
+		}
+	})
+
+	t.Run("allowed emoji entities kept", func(t *testing.T) {
+		detector := NewDetectorWithAllowed([]string{"✅"}).WithHTMLEntities(true)
+		input := "ok &#x2705; bad &#x274C;"
+		if emojis := detector.FindEmojis(input); !reflect.DeepEqual(emojis, []string{"&#x274C;"}) {
+			t.Errorf("FindEmojis() = %v, want [&#x274C;]", emojis)
+		}
+		if result := detector.RemoveEmojis(input); result != "ok &#x2705; bad " {
+			t.Errorf("RemoveEmojis() = %q", result)
+		}
+	})
+
+	t.Run("out of range entity ignored", func(t *testing.T) {
+		detector := NewDetector().WithHTMLEntities(true)
+		if emojis := detector.FindEmojis("&#99999999;"); len(emojis) != 0 {
+			t.Errorf("FindEmojis() = %v, want none", emojis)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package emoji
+
+import (
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// escapeRegex matches \u{XXXXX} code point escapes, \uXXXX\uXXXX UTF-16 surrogate pair
+// escapes and single \uXXXX escapes, in that order of preference.
+var escapeRegex = regexp.MustCompile(`\\u\{([0-9a-fA-F]{1,6})\}|\\u([dD][89abAB][0-9a-fA-F]{2})\\u([dD][c-fC-F][0-9a-fA-F]{2})|\\u([0-9a-fA-F]{4})`)
+
+// WithEscapes enables detection and removal of emojis written as string escapes such
+// as \ud83d\ude00, \u2764 or \u{1F600}, and returns the Detector. A surrogate pair is
+// treated as one escape. Escapes that decode to non-emoji characters are left alone.
+func (d *Detector) WithEscapes(enabled bool) *Detector {
+	d.escapes = enabled
+	return d
+}
+
+// decodeEscape returns the character an escape stands for.
+func decodeEscape(escape string) (rune, bool) {
+	groups := escapeRegex.FindStringSubmatch(escape)
+	if groups == nil {
+		return 0, false
+	}
+
+	parse := func(hex string) rune {
+		code, err := strconv.ParseInt(hex, 16, 32)
+		if err != nil {
+			return utf8.RuneError
+		}
+		return rune(code)
+	}
+
+	switch {
+	case groups[1] != "":
+		return parse(groups[1]), true
+	case groups[2] != "":
+		return utf16.DecodeRune(parse(groups[2]), parse(groups[3])), true
+	default:
+		return parse(groups[4]), true
+	}
+}
+
+// isEmojiEscape reports whether an escape decodes to an emoji that is not allowed.
+func (d *Detector) isEmojiEscape(escape string) bool {
+	r, ok := decodeEscape(escape)
+	return ok && d.isEmojiRune(r) && !d.allowedEmojis[string(r)]
+}
+
+// findEmojiEscapes returns every emoji escape in text, in order of occurrence.
+func (d *Detector) findEmojiEscapes(text string) []string {
+	if !d.escapes {
+		return nil
+	}
+
+	var escapes []string
+	for _, escape := range escapeRegex.FindAllString(text, -1) {
+		if d.isEmojiEscape(escape) {
+			escapes = append(escapes, escape)
+		}
+	}
+	return escapes
+}
+
+// removeEmojiEscapes strips emoji escapes from text.
+func (d *Detector) removeEmojiEscapes(text string) string {
+	if !d.escapes {
+		return text
+	}
+
+	return escapeRegex.ReplaceAllStringFunc(text, func(escape string) string {
+		if d.isEmojiEscape(escape) {
+			return ""
+		}
+		return escape
+	})
+}
@@ -0,0 +1,83 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector_WithEscapes(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowed       []string
+		input         string
+		expectedFound []string
+		expectedClean string
+	}{
+		{
+			name:          "surrogate pair escape",
+			input:         `{"msg": "Hello \ud83d\ude00 world"}`,
+			expectedFound: []string{`\ud83d\ude00`},
+			expectedClean: `{"msg": "Hello  world"}`,
+		},
+		{
+			name:          "uppercase surrogate pair escape",
+			input:         `"\uD83D\uDE80"`,
+			expectedFound: []string{`\uD83D\uDE80`},
+			expectedClean: `""`,
+		},
+		{
+			name:          "BMP escape",
+			input:         `love = "I \u2764 Go"`,
+			expectedFound: []string{`\u2764`},
+			expectedClean: `love = "I  Go"`,
+		},
+		{
+			name:          "code point escape",
+			input:         `let s = "\u{1F600}!";`,
+			expectedFound: []string{`\u{1F600}`},
+			expectedClean: `let s = "!";`,
+		},
+		{
+			name:          "non-emoji escapes untouched",
+			input:         `"caf\u00e9 \u4e2d \u{41} \ud83d alone"`,
+			expectedFound: nil,
+			expectedClean: `"caf\u00e9 \u4e2d \u{41} \ud83d alone"`,
+		},
+		{
+			name:          "allowed emoji escape untouched",
+			allowed:       []string{"✅"},
+			input:         `"\u2705 \u274c"`,
+			expectedFound: []string{`\u274c`},
+			expectedClean: `"\u2705 "`,
+		},
+		{
+			name:          "literal emojis still handled",
+			input:         `"🚀 \u2728"`,
+			expectedFound: []string{"🚀", `\u2728`},
+			expectedClean: `" "`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetectorWithAllowed(tt.allowed).WithEscapes(true)
+			if found := detector.FindEmojis(tt.input); !reflect.DeepEqual(found, tt.expectedFound) {
+				t.Errorf("FindEmojis(%q) = %q, want %q", tt.input, found, tt.expectedFound)
+			}
+			if cleaned := detector.RemoveEmojis(tt.input); cleaned != tt.expectedClean {
+				t.Errorf("RemoveEmojis(%q) = %q, want %q", tt.input, cleaned, tt.expectedClean)
+			}
+		})
+	}
+}
+
+func TestDetector_EscapesDisabledByDefault(t *testing.T) {
+	input := `"\ud83d\ude00"`
+	detector := NewDetector()
+	if found := detector.FindEmojis(input); len(found) != 0 {
+		t.Errorf("Expected escapes to be ignored by default, got %q", found)
+	}
+	if cleaned := detector.RemoveEmojis(input); cleaned != input {
+		t.Errorf("Expected escapes to be kept by default, got %q", cleaned)
+	}
+}
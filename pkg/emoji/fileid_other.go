@@ -0,0 +1,8 @@
+//go:build !unix
+
+package emoji
+
+// hardlinkID is not supported on this platform, so hardlinks are never deduplicated.
+func hardlinkID(_ string) (id fileID, ok bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package emoji
+
+import (
+	"os"
+	"syscall"
+)
+
+// hardlinkID returns the device and inode identifying a file with more than one
+// hard link. Files with a single link cannot be reached twice, so ok is false.
+func hardlinkID(path string) (id fileID, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileID{}, false
+	}
+	stat, isStat := info.Sys().(*syscall.Stat_t)
+	if !isStat || uint64(stat.Nlink) < 2 {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true // #nosec G115 -- device numbers are non-negative
+}
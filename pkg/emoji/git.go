@@ -0,0 +1,33 @@
+package emoji
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitTrackedFiles returns the files git tracks under dir, as the paths a walk of dir
+// produces (dir joined with each file's relative path). It fails if dir is not inside
+// a git repository or git is not installed.
+func GitTrackedFiles(dir string) (map[string]bool, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "ls-files", "-z") // #nosec G204 -- dir is the user-provided directory argument
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("failed to list git tracked files in %s: %s", dir, msg)
+		}
+		return nil, fmt.Errorf("failed to list git tracked files in %s: %w", dir, err)
+	}
+
+	tracked := make(map[string]bool)
+	for _, rel := range strings.Split(stdout.String(), "\x00") {
+		if rel != "" {
+			tracked[filepath.Join(dir, rel)] = true
+		}
+	}
+	return tracked, nil
+}
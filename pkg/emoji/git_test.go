@@ -0,0 +1,68 @@
+package emoji
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initGitRepo creates a git repository in dir with the given files staged, so they
+// are tracked without needing a commit.
+func initGitRepo(t *testing.T, dir string, tracked ...string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	args := [][]string{{"init", "-q"}, append([]string{"add", "--"}, tracked...)}
+	for _, arg := range args {
+		cmd := exec.Command("git", append([]string{"-C", dir}, arg...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", arg, err, output)
+		}
+	}
+}
+
+func TestFileProcessor_GitTrackedOnly(t *testing.T) {
+	dir := t.TempDir()
+	tracked := filepath.Join(dir, "tracked.txt")
+	untracked := filepath.Join(dir, "build", "untracked.txt")
+	if err := os.MkdirAll(filepath.Dir(untracked), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{tracked, untracked} {
+		if err := os.WriteFile(file, []byte("Hello 😊"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	initGitRepo(t, dir, "tracked.txt")
+
+	files, err := GitTrackedFiles(dir)
+	if err != nil {
+		t.Fatalf("GitTrackedFiles() error = %v", err)
+	}
+	if len(files) != 1 || !files[tracked] {
+		t.Fatalf("GitTrackedFiles() = %v, want only %s", files, tracked)
+	}
+
+	fp := NewFileProcessor()
+	fp.OnlyFiles = files
+	results, err := fp.ProcessDirectory(dir, true)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+	if len(results) != 1 || results[0].FilePath != tracked {
+		t.Errorf("Expected only the tracked file to be processed, got %+v", results)
+	}
+}
+
+func TestGitTrackedFiles_OutsideRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir)) // Don't find a repository above the temp dir
+	if _, err := GitTrackedFiles(dir); err == nil {
+		t.Error("Expected an error outside a git repository")
+	}
+}
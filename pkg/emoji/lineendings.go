@@ -0,0 +1,35 @@
+package emoji
+
+import (
+	"slices"
+	"strings"
+)
+
+// lineEndings returns the line endings of text in order, each "\n" or "\r\n". A
+// carriage return not followed by a newline is content, not a line ending.
+func lineEndings(text string) []string {
+	var endings []string
+	for offset := 0; ; {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return endings
+		}
+		i += offset
+		if i > 0 && text[i-1] == '\r' {
+			endings = append(endings, "\r\n")
+		} else {
+			endings = append(endings, "\n")
+		}
+		offset = i + 1
+	}
+}
+
+// keepsLineEndings reports whether removing emojis from original left every line
+// ending as it was. Removal only touches emojis, so endings change only when an emoji
+// sat between a carriage return and its newline or a replacement added line breaks;
+// such a file is reported with LineEndingConflict set rather than written with some of
+// its lines converted. The line-based post-processing keeps each line's own ending, so
+// it is checked against the text before that step.
+func keepsLineEndings(original, removed string) bool {
+	return slices.Equal(lineEndings(original), lineEndings(removed))
+}
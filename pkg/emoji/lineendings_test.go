@@ -0,0 +1,88 @@
+package emoji
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLineEndings(t *testing.T) {
+	got := lineEndings("a\r\nb\nc\rd\n\r\n\nend")
+	want := []string{"\r\n", "\n", "\n", "\r\n", "\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lineEndings() = %q, want %q", got, want)
+	}
+	if got := lineEndings("no breaks"); got != nil {
+		t.Errorf("lineEndings() = %q, want nil", got)
+	}
+}
+
+func TestFileProcessor_MixedLineEndings(t *testing.T) {
+	original := "unix 🚀 line\nwindows 😊 line\r\n✅\r\n🚀\n\r\nplain\r\nlast ✅"
+	tests := []struct {
+		name      string
+		configure func(*FileProcessor)
+		want      string
+	}{
+		{"remove", func(*FileProcessor) {}, "unix  line\nwindows  line\r\n\r\n\n\r\nplain\r\nlast "},
+		{"delete emoji-only lines", func(fp *FileProcessor) { fp.DeleteEmojiOnlyLines = true }, "unix  line\nwindows  line\r\n\r\nplain\r\nlast "},
+		{"squeeze blank lines", func(fp *FileProcessor) { fp.SqueezeBlankLines = true }, "unix  line\nwindows  line\r\n\r\nplain\r\nlast "},
+		{"collapse whitespace", func(fp *FileProcessor) { fp.Detector.WithWhitespacePolicy(WhitespaceCollapseBoth) }, "unix line\nwindows line\r\n\r\n\n\r\nplain\r\nlast"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "mixed.txt")
+			if err := os.WriteFile(file, []byte(original), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			fp := NewFileProcessor()
+			tt.configure(fp)
+			if _, err := fp.ProcessFile(file, false); err != nil {
+				t.Fatalf("ProcessFile() error = %v", err)
+			}
+			got, _ := os.ReadFile(file)
+			if string(got) != tt.want {
+				t.Errorf("cleaned = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileProcessor_ReportsLineEndingConflicts(t *testing.T) {
+	dir := t.TempDir()
+	between := filepath.Join(dir, "between.txt")
+	newlines := filepath.Join(dir, "newlines.txt")
+	original := map[string]string{
+		between:  "old mac\r🚀\nline\r\n", // Removing the emoji would turn the lone CR into CRLF
+		newlines: "one 😊 two\r\n",
+	}
+	for path, content := range original {
+		_ = os.WriteFile(path, []byte(content), 0600)
+	}
+
+	fp := NewFileProcessor()
+	fp.Detector.WithReplacements(map[string]string{"😊": "\n"})
+	results, err := fp.ProcessDirectory(dir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	// The emojis are still reported, but neither file is written
+	want := map[string][]string{between: {"🚀"}, newlines: {"😊"}}
+	if len(results) != 2 {
+		t.Fatalf("Expected both files reported, got %+v", results)
+	}
+	for _, result := range results {
+		if !reflect.DeepEqual(result.EmojisFound, want[result.FilePath]) || !result.LineEndingConflict || result.Modified {
+			t.Errorf("Expected %s reported with a line ending conflict, got %+v", result.FilePath, result)
+		}
+	}
+	for path, content := range original {
+		if got, _ := os.ReadFile(path); string(got) != content {
+			t.Errorf("%s was modified: %q", path, got)
+		}
+	}
+}
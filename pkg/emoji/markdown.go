@@ -0,0 +1,194 @@
+package emoji
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// markdownSegment is a run of a markdown document that is either code, a fenced code
+// block or an inline code span including its delimiters, or prose.
+type markdownSegment struct {
+	text string
+	code bool
+}
+
+// isMarkdown reports whether path has a markdown extension.
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// processMarkdown is ProcessFile for a markdown file with MarkdownAware set. Emojis in
+// prose are reported and removed; emojis in fenced code blocks and inline code spans are
+// left in place and reported in PreservedEmojis.
+func (fp *FileProcessor) processMarkdown(d *Detector, filePath, text string, dryRun bool) (ProcessResult, error) {
+	result := ProcessResult{
+		FilePath:     filePath,
+		OriginalSize: int64(len(text)),
+		Protected:    fp.isProtected(filePath),
+	}
+	if result.Protected {
+		dryRun = true
+	}
+
+	var cleaned strings.Builder
+	cleaned.Grow(len(text))
+	var prose, code []string
+	for _, segment := range splitMarkdownCode(text) {
+		if segment.code {
+			cleaned.WriteString(segment.text)
+			code = append(code, segment.text)
+			continue
+		}
+		cleaned.WriteString(fp.removeEmojis(d, segment.text))
+		prose = append(prose, segment.text)
+	}
+
+	// Segments are joined on newlines so no emoji sequence spans two of them
+	joined := strings.Join(prose, "\n")
+	result.EmojisFound = d.FindEmojis(joined)
+	if len(result.EmojisFound) == 0 {
+		return result, nil
+	}
+	result.PreservedEmojis = d.FindEmojis(strings.Join(code, "\n"))
+	result.Occurrences = fp.countOccurrences(d, joined)
+	result.Density = fp.density(d, joined)
+	result.Replacements = d.Substitutions(joined, fp.ASCIIFallback)
+	if !keepsLineEndings(text, cleaned.String()) {
+		result.LineEndingConflict = true
+		return result, nil
+	}
+
+	return fp.finishResult(result, fp.postProcess(text, cleaned.String()), dryRun)
+}
+
+// splitMarkdownCode splits markdown text into prose and code segments, which
+// concatenate back to text. Fences follow CommonMark: a line of at least three backticks
+// or tildes, indented by at most three spaces, opens a block that only a line of the same
+// character at least as long closes, so shorter or different fences inside it are
+// content. An unclosed fence runs to the end of the document. In prose, a run of
+// backticks opens an inline code span closed by the next run of the same length; a run
+// with no match is literal text.
+func splitMarkdownCode(text string) []markdownSegment {
+	var segments []markdownSegment
+	proseStart := 0
+	for i := 0; i < len(text); {
+		lineEnd := nextLine(text, i)
+		fence, length, ok := openingFence(text[i:lineEnd])
+		if !ok {
+			i = lineEnd
+			continue
+		}
+
+		segments = appendProse(segments, text[proseStart:i])
+		end := lineEnd
+		for end < len(text) {
+			next := nextLine(text, end)
+			closed := closesFence(text[end:next], fence, length)
+			end = next
+			if closed {
+				break
+			}
+		}
+		segments = append(segments, markdownSegment{text: text[i:end], code: true})
+		proseStart, i = end, end
+	}
+	return appendProse(segments, text[proseStart:])
+}
+
+// nextLine returns the index just past the line of text starting at i, newline included.
+func nextLine(text string, i int) int {
+	if n := strings.IndexByte(text[i:], '\n'); n >= 0 {
+		return i + n + 1
+	}
+	return len(text)
+}
+
+// fenceRun returns the fence character and run length at the start of line after at
+// most three spaces of indentation, along with the rest of the line.
+func fenceRun(line string) (byte, int, string) {
+	line = strings.TrimRight(line, "\r\n")
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 || indent == len(line) {
+		return 0, 0, ""
+	}
+	line = line[indent:]
+	fence := line[0]
+	if fence != '`' && fence != '~' {
+		return 0, 0, ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, string(fence)))
+	return fence, n, line[n:]
+}
+
+// openingFence reports whether line opens a fenced code block, and with which fence.
+func openingFence(line string) (byte, int, bool) {
+	fence, n, info := fenceRun(line)
+	if n < 3 || (fence == '`' && strings.Contains(info, "`")) {
+		return 0, 0, false
+	}
+	return fence, n, true
+}
+
+// closesFence reports whether line closes a code block opened by length fence characters.
+func closesFence(line string, fence byte, length int) bool {
+	f, n, rest := fenceRun(line)
+	return f == fence && n >= length && strings.TrimSpace(rest) == ""
+}
+
+// appendProse appends prose to segments, split around its inline code spans.
+func appendProse(segments []markdownSegment, prose string) []markdownSegment {
+	start := 0
+	for i := 0; i < len(prose); {
+		switch prose[i] {
+		case '\\':
+			i += 2 // An escaped backtick cannot open a code span
+			continue
+		case '`':
+		default:
+			i++
+			continue
+		}
+
+		n := backtickRun(prose, i)
+		closing := -1
+		for j := i + n; j < len(prose); {
+			if prose[j] != '`' {
+				j++
+				continue
+			}
+			m := backtickRun(prose, j)
+			if m == n {
+				closing = j
+				break
+			}
+			j += m
+		}
+		if closing < 0 {
+			i += n
+			continue
+		}
+
+		if i > start {
+			segments = append(segments, markdownSegment{text: prose[start:i]})
+		}
+		segments = append(segments, markdownSegment{text: prose[i : closing+n], code: true})
+		start, i = closing+n, closing+n
+	}
+	if start < len(prose) {
+		segments = append(segments, markdownSegment{text: prose[start:]})
+	}
+	return segments
+}
+
+// backtickRun returns the number of consecutive backticks at s[i:].
+func backtickRun(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,102 @@
+package emoji
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitMarkdownCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  []string
+	}{
+		{"no code", "Just prose\n", nil},
+		{"fenced block", "Intro\n```go\nx := 1\n```\nAfter\n", []string{"```go\nx := 1\n```\n"}},
+		{"tilde fence", "~~~\ncode\n~~~\n", []string{"~~~\ncode\n~~~\n"}},
+		{"shorter fence inside longer one is content", "````\n```\ninner\n```\n````\ntail", []string{"````\n```\ninner\n```\n````\n"}},
+		{"other fence character is content", "```\n~~~\n```\n", []string{"```\n~~~\n```\n"}},
+		{"unterminated fence runs to the end", "Intro\n```\ncode\nmore", []string{"```\ncode\nmore"}},
+		{"fence indented four spaces is not a fence", "    ```\ntext\n", nil},
+		{"inline code span", "Run `go test` now", []string{"`go test`"}},
+		{"double backtick span", "Use ``a ` b`` here", []string{"``a ` b``"}},
+		{"unmatched backtick is literal", "A ` lone tick", nil},
+		{"escaped backtick", "Not \\`code\\` here", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := splitMarkdownCode(tt.input)
+			var joined strings.Builder
+			var code []string
+			for _, segment := range segments {
+				joined.WriteString(segment.text)
+				if segment.code {
+					code = append(code, segment.text)
+				}
+			}
+			if joined.String() != tt.input {
+				t.Errorf("Segments join to %q, want %q", joined.String(), tt.input)
+			}
+			if !reflect.DeepEqual(code, tt.code) {
+				t.Errorf("Code segments = %q, want %q", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestFileProcessor_MarkdownAware(t *testing.T) {
+	tempDir := t.TempDir()
+	input := "# Release 🚀\n\nRun `echo ✅` to test.\n\n```sh\necho \"✨ done\"\n```\n\nShipped ✨\n"
+	path := filepath.Join(tempDir, "README.md")
+	_ = os.WriteFile(path, []byte(input), 0600)
+	txt := filepath.Join(tempDir, "notes.txt")
+	_ = os.WriteFile(txt, []byte("Run `echo ✅`\n"), 0600)
+
+	fp := NewFileProcessor()
+	fp.MarkdownAware = true
+	result, err := fp.ProcessFile(path, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+
+	want := "# Release \n\nRun `echo ✅` to test.\n\n```sh\necho \"✨ done\"\n```\n\nShipped \n"
+	if content, _ := os.ReadFile(path); string(content) != want {
+		t.Errorf("Cleaned content = %q, want %q", content, want)
+	}
+	if !reflect.DeepEqual(result.EmojisFound, []string{"🚀", "✨"}) {
+		t.Errorf("EmojisFound = %q, want prose emojis only", result.EmojisFound)
+	}
+	if !reflect.DeepEqual(result.PreservedEmojis, []string{"✅", "✨"}) {
+		t.Errorf("PreservedEmojis = %q, want code emojis", result.PreservedEmojis)
+	}
+
+	// Only markdown files are parsed
+	if _, err := fp.ProcessFile(txt, false); err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if content, _ := os.ReadFile(txt); string(content) != "Run `echo `\n" {
+		t.Errorf("Non-markdown file should be cleaned as usual, got %q", content)
+	}
+}
+
+func TestFileProcessor_MarkdownAwareCodeOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.md")
+	input := "Example:\n\n```\nstatus: ✅\n"
+	_ = os.WriteFile(path, []byte(input), 0600)
+
+	fp := NewFileProcessor()
+	fp.MarkdownAware = true
+	result, err := fp.ProcessFile(path, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if len(result.EmojisFound) != 0 || result.Modified {
+		t.Errorf("Emojis in an unterminated fence should be kept, got %+v", result)
+	}
+	if content, _ := os.ReadFile(path); string(content) != input {
+		t.Errorf("File should be unchanged, got %q", content)
+	}
+}
@@ -0,0 +1,49 @@
+package emoji
+
+import "slices"
+
+// WithNever excludes code points from detection entirely and returns the Detector.
+// Unlike allowed emojis, which are found and then kept, these are never treated as
+// emojis at all, so they are neither reported nor counted, for symbols such as ⚙ that
+// are ordinary text in some documents. The rest of their range is still detected.
+func (d *Detector) WithNever(never []rune) *Detector {
+	d.never = slices.Clone(never)
+	slices.Sort(d.never)
+	d.never = slices.Compact(d.never)
+	d.rebuildRanges()
+	return d
+}
+
+// rebuildRanges sets the ranges and regex from the BMP-only and never settings.
+func (d *Detector) rebuildRanges() {
+	d.ranges = emojiRanges
+	if d.bmpOnly {
+		d.ranges = bmpRanges
+	}
+	if len(d.never) > 0 {
+		d.ranges = withoutRunes(d.ranges, d.never)
+	}
+	d.emojiRegex = compileEmojiRegex(d.ranges)
+}
+
+// withoutRunes returns ranges with each of never, which must be sorted, cut out,
+// splitting a range where a code point falls inside it.
+func withoutRunes(ranges []runeRange, never []rune) []runeRange {
+	var out []runeRange
+	for _, rr := range ranges {
+		lo := rr.lo
+		for _, r := range never {
+			if r < lo || r > rr.hi {
+				continue
+			}
+			if r > lo {
+				out = append(out, runeRange{lo, r - 1})
+			}
+			lo = r + 1
+		}
+		if lo <= rr.hi {
+			out = append(out, runeRange{lo, rr.hi})
+		}
+	}
+	return out
+}
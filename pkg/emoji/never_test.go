@@ -0,0 +1,96 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector_WithNever(t *testing.T) {
+	detector := NewDetector().WithNever([]rune{'⚙'})
+
+	text := "⚙ Settings 😊 and ⚙️ options"
+	if got, want := detector.RemoveEmojis(text), "⚙ Settings  and ⚙️ options"; got != want {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, want)
+	}
+	if got := detector.FindEmojis(text); !reflect.DeepEqual(got, []string{"😊"}) {
+		t.Errorf("FindEmojis() = %v, want [😊]", got)
+	}
+	if got := detector.Count(text); got != 1 {
+		t.Errorf("Count() = %d, want 1", got)
+	}
+
+	// Only the listed code point is excluded, not the rest of its range
+	if got := detector.RemoveEmojis("⚘⚙⚚"); got != "⚙" {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, "⚙")
+	}
+
+	// Encoded forms of an excluded code point are left alone too
+	if got := NewDetector().WithHTMLEntities(true).WithNever([]rune{'⚙'}).RemoveEmojis("&#x2699; &#x1F60A;"); got != "&#x2699; " {
+		t.Errorf("RemoveEmojis() = %q, want %q", got, "&#x2699; ")
+	}
+
+	// The exclusion holds whichever order it is combined with WithBMPOnly
+	for _, d := range []*Detector{
+		NewDetector().WithNever([]rune{'⚙'}).WithBMPOnly(true),
+		NewDetector().WithBMPOnly(true).WithNever([]rune{'⚙'}),
+	} {
+		if got := d.RemoveEmojis("⚙ ✅ 😊"); got != "⚙  😊" {
+			t.Errorf("RemoveEmojis() = %q, want %q", got, "⚙  😊")
+		}
+	}
+}
+
+func TestWithoutRunes(t *testing.T) {
+	ranges := []runeRange{{0x10, 0x12}, {0x20, 0x25}}
+	tests := []struct {
+		name  string
+		never []rune
+		want  []runeRange
+	}{
+		{"outside every range", []rune{0x05, 0x15, 0x30}, ranges},
+		{"range bounds", []rune{0x10, 0x25}, []runeRange{{0x11, 0x12}, {0x20, 0x24}}},
+		{"inside a range", []rune{0x22}, []runeRange{{0x10, 0x12}, {0x20, 0x21}, {0x23, 0x25}}},
+		{"adjacent code points", []rune{0x22, 0x23}, []runeRange{{0x10, 0x12}, {0x20, 0x21}, {0x24, 0x25}}},
+		{"a whole range", []rune{0x10, 0x11, 0x12}, []runeRange{{0x20, 0x25}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withoutRunes(ranges, tt.never); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withoutRunes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetector_WithNeverEverything(t *testing.T) {
+	var all []rune
+	for _, rr := range bmpRanges {
+		for r := rr.lo; r <= rr.hi; r++ {
+			all = append(all, r)
+		}
+	}
+
+	// With every range excluded nothing matches, not even the empty string
+	text := "Hello ✅ ⚙"
+	for _, d := range []*Detector{
+		NewDetector().WithBMPOnly(true).WithNever(all),
+		NewDetectorRegexOnly().WithBMPOnly(true).WithNever(all),
+	} {
+		if len(d.Ranges()) != 0 {
+			t.Fatalf("Ranges() = %v, want none", d.Ranges())
+		}
+		if got := d.RemoveEmojis(text); got != text {
+			t.Errorf("RemoveEmojis() = %q, want %q", got, text)
+		}
+		if got := d.FindEmojis(text); len(got) != 0 {
+			t.Errorf("FindEmojis() = %q, want none", got)
+		}
+		if got := d.Count(text); got != 0 {
+			t.Errorf("Count() = %d, want 0", got)
+		}
+	}
+	if compileEmojiRegex(nil).MatchString("") {
+		t.Error("Regex for no ranges matches the empty string")
+	}
+}
@@ -1,26 +1,284 @@
 package emoji
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
 )
 
+// ErrWriteInDryRun is returned when a write is attempted during a dry run while AssertNoWrites is set.
+var ErrWriteInDryRun = errors.New("write attempted during dry run")
+
+// ErrReadFile is returned when a file cannot be read. ProcessDirectoryContext treats it
+// as a per-file warning rather than a fatal error.
+var ErrReadFile = errors.New("failed to read file")
+
 // FileProcessor handles processing files to remove emojis.
 type FileProcessor struct {
 	Detector *Detector // Made public so commands can access it
 	excludes []string
+
+	// NoSkipBinary disables skipping files by their binary extension. Files are
+	// then only skipped by the exclusion patterns or for not being valid UTF-8.
+	NoSkipBinary bool
+
+	// SkipBinaryByMIME also skips files whose content net/http.DetectContentType
+	// does not sniff as text/*, such as a PDF without a .pdf extension. Skipped
+	// paths are recorded in Skipped with the detected type.
+	SkipBinaryByMIME bool
+
+	// InputCharset, if set, is the encoding of the files processed. Content is
+	// decoded from it before detection and cleaned content is encoded back to it
+	// when written, so bytes outside UTF-8 survive. Archives are still read as UTF-8.
+	InputCharset encoding.Encoding
+
+	// ScanGzip enables read-only scanning of .gz files. Their contents are
+	// decompressed in memory and reported, but never rewritten.
+	ScanGzip bool
+
+	// ScanZip enables read-only scanning of text entries inside .zip archives.
+	// Entries are reported as zip://archive.zip!entry and never rewritten.
+	ScanZip bool
+
+	// ExcludeRegexps are matched against the full path in addition to the
+	// glob-style exclusion patterns.
+	ExcludeRegexps []*regexp.Regexp
+
+	// Checkpoint, if set, records each processed file, and files it already
+	// holds are skipped so an interrupted walk can be resumed.
+	Checkpoint *Checkpoint
+
+	// OnlyFiles, if non-nil, limits directory walks to these files, given as the
+	// paths the walk produces (see GitTrackedFiles). Other filters still apply.
+	OnlyFiles map[string]bool
+
+	// Protect lists patterns, matched like exclusion patterns, for files that are
+	// scanned and reported but never written, even when not in dry-run mode.
+	Protect []string
+
+	// AssertNoWrites makes any write attempted during a dry run fail with
+	// ErrWriteInDryRun instead of touching the file.
+	AssertNoWrites bool
+
+	// AtomicWrites writes cleaned content to a temporary file in the same
+	// directory and renames it over the original, so the original is never
+	// truncated and readers see either the old or the new content.
+	AtomicWrites bool
+
+	// SqueezeBlankLines collapses runs of blank lines left behind by removing
+	// emoji-only lines. Blank lines that existed before cleaning are kept.
+	SqueezeBlankLines bool
+
+	// ReportEmojiOnlyLines records the lines whose only non-whitespace content is
+	// emojis in each ProcessResult. DeleteEmojiOnlyLines also removes those lines
+	// entirely instead of leaving them blank, which makes SqueezeBlankLines moot.
+	ReportEmojiOnlyLines bool
+	DeleteEmojiOnlyLines bool
+
+	// ASCIIFallback replaces emojis with plain-text equivalents where one exists
+	// instead of removing them.
+	ASCIIFallback bool
+
+	// DedupeHardlinks skips files that are hard links to a file already processed
+	// in this run. Skipped paths are recorded in Deduped.
+	DedupeHardlinks bool
+	Deduped         []string
+	seenFiles       map[fileID]string
+	seenMu          sync.Mutex // Guards seenFiles, Deduped and Skipped for concurrent processing
+
+	// IncludeModTime records each file's modification time in its ProcessResult.
+	IncludeModTime bool
+
+	// HashCleaned records the SHA-256 of each file's cleaned content in its
+	// ProcessResult, computed from the in-memory bytes even in a dry run.
+	HashCleaned bool
+
+	// RecordFirstEmoji records where the first emoji in each file occurs in its
+	// ProcessResult, for compact previews of large reports.
+	RecordFirstEmoji bool
+
+	// CountOccurrences records the total number of emoji occurrences, counting
+	// repeats, in each ProcessResult.
+	CountOccurrences bool
+
+	// RecordDensity records in each ProcessResult the emojis found as a percentage of
+	// the file's non-whitespace characters (see Detector.Density).
+	RecordDensity bool
+
+	// ColumnMode controls how reported columns are counted. The zero value
+	// counts characters, like ColumnRune.
+	ColumnMode ColumnMode
+
+	// ModifiedSince skips files last modified before this time. The zero time
+	// disables the filter.
+	ModifiedSince time.Time
+
+	// MinFileSize and MaxFileSize skip files smaller or larger than the given
+	// number of bytes. Both bounds are inclusive and zero disables a bound.
+	// Skipped paths are recorded in Skipped with the reason.
+	MinFileSize int64
+	MaxFileSize int64
+	Skipped     []SkippedFile
+
+	// MaxLines skips files with more than this many lines, counted as newlines
+	// plus an unterminated last line, once the file has been read. Skipped paths
+	// are recorded in Skipped. Zero disables the limit.
+	MaxLines int
+
+	// SkipSymlinks skips symbolic links met while walking a directory, so nothing is
+	// written through a link, and records them in Skipped. Directories are walked
+	// as usual.
+	SkipSymlinks bool
+
+	// Structured, if set, cleans files of that format (by extension) by removing
+	// emojis from string values only, leaving keys and structure alone. Files that
+	// fail to parse are recorded in Skipped.
+	Structured StructuredFormat
+
+	// MarkdownAware cleans .md and .markdown files outside code only: emojis in
+	// fenced code blocks and inline code spans are kept and reported in
+	// ProcessResult.PreservedEmojis. Files whose only emojis are in code are not
+	// reported, as with allowed emojis.
+	MarkdownAware bool
+
+	// AllowByExtension maps lower-case extensions, such as ".md", to the emojis
+	// allowed in files with that extension in place of the Detector's own list
+	// (see AllowConfig). Other Detector settings still apply.
+	AllowByExtension map[string][]string
+	extDetectors     map[string]*Detector
+	extBase          *Detector  // The Detector extDetectors were cloned from
+	extMu            sync.Mutex // Guards extDetectors and extBase
+
+	// Limit stops processing once this many files containing emojis have been
+	// collected. Zero means no limit.
+	Limit int
+
+	// Workers is the number of goroutines ProcessDirectoryContext processes files
+	// with. Zero means runtime.NumCPU().
+	Workers int
+
+	// MaxOpenFiles caps how many files ProcessDirectoryContext has open at once,
+	// independently of Workers, for filesystems where open handles are scarce.
+	// Zero means no cap beyond the number of workers.
+	MaxOpenFiles int
+
+	// WriteBatchSize, if positive, makes ProcessDirectoryContext buffer cleaned files
+	// and write them in batches of this many, in parallel and each atomically, while
+	// the workers go on cleaning. Batches left over are written before it returns.
+	WriteBatchSize int
+	batch          *writeBatch
+
+	// Warnings collects per-file errors, such as unreadable files, that
+	// ProcessDirectoryContext skipped past instead of failing.
+	Warnings []error
+
+	// WarnOnEmpty records in ProcessResult.Emptied whether cleaning leaves a file
+	// blank. RefuseEmpty also leaves such files unwritten, as in a dry run.
+	WarnOnEmpty bool
+	RefuseEmpty bool
+
+	// OnFileProcessed, if set, is called after each file or archive a directory
+	// walk processes, whether or not it contained emojis. ProcessDirectoryContext
+	// calls it from several goroutines at once.
+	OnFileProcessed func(path string)
+
+	// ReadFile, if set, reads the files ProcessFile processes in place of os.ReadFile,
+	// so embedders can instrument or redirect file IO. ProcessDirectoryContext calls
+	// it from several goroutines at once.
+	ReadFile func(path string) ([]byte, error)
+
+	// SkipFunc, if set, is consulted for each file a directory walk reaches after
+	// the built-in filters have let it through, so embedders can add their own
+	// rules. Files it skips are recorded in Skipped when it gives a reason.
+	SkipFunc func(path string, info fs.FileInfo) (skip bool, reason string)
+}
+
+// SkippedFile records a file that was left unprocessed and why.
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// fileID identifies a file on disk independently of the path used to reach it.
+type fileID struct {
+	dev uint64
+	ino uint64
 }
 
-// ProcessResult contains the results of processing a single file.
+// ProcessResult contains the results of processing a single file. It can be
+// marshaled to and from JSON directly to pass results between tools.
 type ProcessResult struct {
-	FilePath     string
-	EmojisFound  []string
-	OriginalSize int64
-	NewSize      int64
-	Modified     bool
+	FilePath     string        `json:"file_path"`
+	EmojisFound  []string      `json:"emojis_found"`
+	OriginalSize int64         `json:"original_size"`
+	NewSize      int64         `json:"new_size"`
+	Modified     bool          `json:"modified"`
+	ModifiedTime time.Time     `json:"modified_time"` // Zero unless IncludeModTime is set and the input is a file
+	FirstEmoji   EmojiLocation `json:"first_emoji"`   // Zero unless RecordFirstEmoji is set and the input is a file
+
+	// EmojiOnlyLines lists the 1-based lines whose only non-whitespace content is
+	// emojis. Empty unless ReportEmojiOnlyLines or DeleteEmojiOnlyLines is set.
+	EmojiOnlyLines []int `json:"emoji_only_lines,omitempty"`
+
+	// CleanedSHA256 is the hex SHA-256 of the cleaned content. Empty unless
+	// HashCleaned is set and the file was cleaned.
+	CleanedSHA256 string `json:"cleaned_sha256,omitempty"`
+
+	// Protected is set for files matching a Protect pattern, which are processed
+	// as in a dry run whatever mode was requested.
+	Protected bool `json:"protected,omitempty"`
+
+	// Occurrences is the number of emojis found, counting repeats, whereas
+	// EmojisFound lists each emoji once. Zero unless CountOccurrences is set.
+	Occurrences int `json:"occurrences,omitempty"`
+
+	// Emptied is set when the cleaned content is empty or whitespace-only, which
+	// usually means the file held nothing but emojis. Only recorded with
+	// WarnOnEmpty or RefuseEmpty. WriteRefused is set when RefuseEmpty kept such a
+	// file from being written.
+	Emptied      bool `json:"emptied,omitempty"`
+	WriteRefused bool `json:"write_refused,omitempty"`
+
+	// LineEndingConflict is set when removing the emojis found would change the
+	// file's line endings, for example an emoji between a carriage return and its
+	// newline. The emojis are reported but the file is not cleaned or written.
+	LineEndingConflict bool `json:"line_ending_conflict,omitempty"`
+
+	// PreservedEmojis lists the emojis left in code, each once. Only recorded
+	// for markdown files with MarkdownAware set.
+	PreservedEmojis []string `json:"preserved_emojis,omitempty"`
+
+	// Replacements maps each emoji that was substituted rather than removed, by a
+	// replacement map or ASCIIFallback, to what it became.
+	Replacements map[string]string `json:"replacements,omitempty"`
+
+	// Density is the emojis found as a percentage of the non-whitespace characters.
+	// Zero unless RecordDensity is set.
+	Density float64 `json:"density,omitempty"`
+
+	// Cleaned is the content with emojis removed, or the content unchanged if nothing
+	// was removed. Only set by ProcessString.
+	Cleaned string `json:"-"`
+}
+
+// EmojiLocation is an emoji occurrence and where it starts in a file.
+type EmojiLocation struct {
+	Emoji  string `json:"emoji"`
+	Line   int    `json:"line"`   // 1-based
+	Column int    `json:"column"` // 1-based, counted according to ColumnMode
 }
 
 // NewFileProcessor creates a new file processor with an emoji Detector.
@@ -56,24 +314,23 @@ func (fp *FileProcessor) ProcessDirectory(dirPath string, dryRun bool) ([]Proces
 			return err
 		}
 
-		// Check if path should be excluded
-		if fp.isExcluded(path) {
-			if d.IsDir() {
-				return fs.SkipDir
-			}
-			return nil
-		}
-
-		if d.IsDir() {
-			return nil
-		}
-
-		// Skip files in .git directories and other version control directories
-		if strings.Contains(path, "/.git/") || strings.Contains(path, "/.svn/") || strings.Contains(path, "/.hg/") {
-			return nil
+		process, modTime, err := fp.walkFilter(path, d)
+		if !process {
+			return err
 		}
 
-		if shouldSkipFile(path) {
+		if fp.IsScannableZip(path) {
+			zipResults, err := fp.ScanZipArchive(path)
+			if err != nil {
+				return fmt.Errorf("failed to process %s: %w", path, err)
+			}
+			fp.fileProcessed(path)
+			for _, result := range zipResults {
+				results = append(results, result)
+				if fp.LimitReached(len(results)) {
+					return fs.SkipAll
+				}
+			}
 			return nil
 		}
 
@@ -81,9 +338,16 @@ func (fp *FileProcessor) ProcessDirectory(dirPath string, dryRun bool) ([]Proces
 		if err != nil {
 			return fmt.Errorf("failed to process %s: %w", path, err)
 		}
+		fp.fileProcessed(path)
+		if fp.IncludeModTime {
+			result.ModifiedTime = modTime
+		}
 
 		if len(result.EmojisFound) > 0 {
 			results = append(results, result)
+			if fp.LimitReached(len(results)) {
+				return fs.SkipAll
+			}
 		}
 
 		return nil
@@ -92,44 +356,549 @@ func (fp *FileProcessor) ProcessDirectory(dirPath string, dryRun bool) ([]Proces
 	return results, err
 }
 
+// walkFilter applies exclusions, file type, size and modification time filters, then
+// SkipFunc, to an entry of a directory walk. It reports whether the entry is a file to
+// process, along with its modification time when a filter or IncludeModTime needed
+// it. Excluded directories return fs.SkipDir.
+func (fp *FileProcessor) walkFilter(path string, d fs.DirEntry) (bool, time.Time, error) {
+	// Check if path should be excluded
+	if fp.isExcluded(path) {
+		if d.IsDir() {
+			return false, time.Time{}, fs.SkipDir
+		}
+		return false, time.Time{}, nil
+	}
+
+	if d.IsDir() {
+		return false, time.Time{}, nil
+	}
+
+	if fp.SkipSymlinks && d.Type()&fs.ModeSymlink != 0 {
+		fp.recordSkipped(path, "symbolic link")
+		return false, time.Time{}, nil
+	}
+
+	// Skip files in .git directories and other version control directories
+	if strings.Contains(path, "/.git/") || strings.Contains(path, "/.svn/") || strings.Contains(path, "/.hg/") {
+		return false, time.Time{}, nil
+	}
+
+	if fp.shouldSkip(path) {
+		return false, time.Time{}, nil
+	}
+
+	if fp.OnlyFiles != nil && !fp.OnlyFiles[filepath.Clean(path)] {
+		return false, time.Time{}, nil
+	}
+
+	if fp.Checkpoint != nil && fp.Checkpoint.Done(path) {
+		return false, time.Time{}, nil
+	}
+
+	if fp.MinFileSize > 0 || fp.MaxFileSize > 0 {
+		if info, err := d.Info(); err == nil && fp.SkipForSize(path, info.Size()) {
+			return false, time.Time{}, nil
+		}
+	}
+
+	// Capture the modification time before processing may rewrite the file
+	var modTime time.Time
+	if fp.IncludeModTime || !fp.ModifiedSince.IsZero() {
+		if info, err := d.Info(); err == nil {
+			modTime = info.ModTime()
+		}
+		if fp.IsStale(modTime) {
+			return false, time.Time{}, nil
+		}
+	}
+
+	if fp.SkipFunc != nil {
+		if info, err := d.Info(); err == nil {
+			if skip, reason := fp.SkipFunc(path, info); skip {
+				if reason != "" {
+					fp.recordSkipped(path, reason)
+				}
+				return false, time.Time{}, nil
+			}
+		}
+	}
+
+	return true, modTime, nil
+}
+
+// fileProcessed records a processed path in the Checkpoint and reports it to
+// OnFileProcessed, if set.
+func (fp *FileProcessor) fileProcessed(path string) {
+	if fp.Checkpoint != nil {
+		fp.Checkpoint.Record(path)
+	}
+	if fp.OnFileProcessed != nil {
+		fp.OnFileProcessed(path)
+	}
+}
+
+// IsStale reports whether a file modified at modTime falls before the ModifiedSince cutoff.
+func (fp *FileProcessor) IsStale(modTime time.Time) bool {
+	return !fp.ModifiedSince.IsZero() && modTime.Before(fp.ModifiedSince)
+}
+
+// SkipForSize reports whether a file of the given size falls outside the MinFileSize
+// and MaxFileSize bounds, recording it in Skipped if so.
+func (fp *FileProcessor) SkipForSize(path string, size int64) bool {
+	var reason string
+	switch {
+	case fp.MinFileSize > 0 && size < fp.MinFileSize:
+		reason = fmt.Sprintf("size %d bytes is below the minimum of %d bytes", size, fp.MinFileSize)
+	case fp.MaxFileSize > 0 && size > fp.MaxFileSize:
+		reason = fmt.Sprintf("size %d bytes is above the maximum of %d bytes", size, fp.MaxFileSize)
+	default:
+		return false
+	}
+
+	fp.recordSkipped(path, reason)
+	return true
+}
+
+// countLines returns the number of lines in content: its newlines, plus one for a
+// last line without a trailing newline.
+func countLines(content []byte) int {
+	lines := bytes.Count(content, []byte{'\n'})
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// recordSkipped adds a path and the reason it was left unprocessed to Skipped.
+func (fp *FileProcessor) recordSkipped(path, reason string) {
+	fp.seenMu.Lock()
+	defer fp.seenMu.Unlock()
+	fp.Skipped = append(fp.Skipped, SkippedFile{Path: path, Reason: reason})
+}
+
+// LimitReached reports whether count files with emojis satisfy the configured Limit.
+func (fp *FileProcessor) LimitReached(count int) bool {
+	return fp.Limit > 0 && count >= fp.Limit
+}
+
 // ProcessFile processes a single file to find and optionally remove emojis.
 func (fp *FileProcessor) ProcessFile(filePath string, dryRun bool) (ProcessResult, error) {
-	content, err := os.ReadFile(filePath) // #nosec G304 -- filePath is user-provided directory path
+	if fp.isDuplicateHardlink(filePath) {
+		return ProcessResult{FilePath: filePath}, nil
+	}
+
+	if fp.isScannableGzip(filePath) {
+		return fp.scanGzipFile(filePath)
+	}
+
+	content, err := fp.readFile(filePath)
 	if err != nil {
-		return ProcessResult{FilePath: filePath}, fmt.Errorf("failed to read file: %w", err)
+		return ProcessResult{FilePath: filePath}, fmt.Errorf("%w: %w", ErrReadFile, err)
 	}
 
-	originalText := string(content)
-	emojis := fp.Detector.FindEmojis(originalText)
+	result, err := fp.processContent(filePath, content, dryRun)
+	result.Cleaned = ""
+	return result, err
+}
+
+// readFile reads filePath with ReadFile if set, or os.ReadFile.
+func (fp *FileProcessor) readFile(filePath string) ([]byte, error) {
+	if fp.ReadFile != nil {
+		return fp.ReadFile(filePath)
+	}
+	return os.ReadFile(filePath) // #nosec G304 -- filePath is user-provided directory path
+}
+
+// ProcessString processes content as if it had been read from a file called name, and
+// returns the result ProcessFile would, with the cleaned content in Cleaned. Nothing is
+// written, so dryRun only affects the fields that describe a write, such as WriteRefused.
+// name decides the same settings a path does, such as markdown handling and allow-list
+// sections, but is never opened.
+func (fp *FileProcessor) ProcessString(name, content string, dryRun bool) (ProcessResult, error) {
+	result, err := fp.processContent(name, []byte(content), true)
+	if err != nil {
+		return result, err
+	}
+	if !result.Modified {
+		result.Cleaned = content
+	}
+	if !dryRun && !result.Protected && result.Emptied && fp.RefuseEmpty {
+		result.WriteRefused = true
+	}
+	return result, nil
+}
+
+// processContent processes the content of filePath, writing the cleaned content back
+// unless dryRun is set.
+func (fp *FileProcessor) processContent(filePath string, content []byte, dryRun bool) (ProcessResult, error) {
+	// Without extension-based skipping, only valid UTF-8 is treated as text
+	if fp.NoSkipBinary && fp.InputCharset == nil && !utf8.Valid(content) {
+		return ProcessResult{FilePath: filePath, OriginalSize: int64(len(content))}, nil
+	}
+
+	if fp.SkipBinaryByMIME {
+		if mimeType := http.DetectContentType(content); !strings.HasPrefix(mimeType, "text/") {
+			fp.recordSkipped(filePath, "content detected as "+mimeType)
+			return ProcessResult{FilePath: filePath, OriginalSize: int64(len(content))}, nil
+		}
+	}
+
+	if fp.MaxLines > 0 {
+		if lines := countLines(content); lines > fp.MaxLines {
+			fp.recordSkipped(filePath, fmt.Sprintf("%d lines is above the maximum of %d lines", lines, fp.MaxLines))
+			return ProcessResult{FilePath: filePath, OriginalSize: int64(len(content))}, nil
+		}
+	}
+
+	originalText, err := fp.decodeInput(content)
+	if err != nil {
+		return ProcessResult{FilePath: filePath, OriginalSize: int64(len(content))}, err
+	}
+	detector := fp.detectorFor(filePath)
+	if fp.Structured.Matches(filePath) {
+		result, err := fp.processStructured(detector, filePath, originalText, dryRun)
+		result.OriginalSize = int64(len(content))
+		return result, err
+	}
+	if fp.MarkdownAware && isMarkdown(filePath) {
+		result, err := fp.processMarkdown(detector, filePath, originalText, dryRun)
+		result.OriginalSize = int64(len(content))
+		return result, err
+	}
+	removed, emojis := fp.stripAndReport(detector, originalText)
 
 	result := ProcessResult{
 		FilePath:     filePath,
 		EmojisFound:  emojis,
 		OriginalSize: int64(len(content)),
 		Modified:     false,
+		Protected:    fp.isProtected(filePath),
+	}
+	if result.Protected {
+		dryRun = true
 	}
 
 	if len(emojis) == 0 {
 		return result, nil
 	}
 
-	cleanedText := fp.Detector.RemoveEmojis(originalText)
-	result.NewSize = int64(len(cleanedText))
+	if fp.RecordFirstEmoji {
+		result.FirstEmoji = fp.firstEmojiLocation(detector, originalText)
+	}
+
+	result.Occurrences = fp.countOccurrences(detector, originalText)
+	result.Density = fp.density(detector, originalText)
+	result.Replacements = detector.Substitutions(originalText, fp.ASCIIFallback)
+
+	if !keepsLineEndings(originalText, removed) {
+		result.LineEndingConflict = true
+		return result, nil
+	}
+	if fp.ReportEmojiOnlyLines || fp.DeleteEmojiOnlyLines {
+		result.EmojiOnlyLines = emojiOnlyLines(originalText, removed)
+	}
+
+	return fp.finishResult(result, fp.postProcess(originalText, removed), dryRun)
+}
+
+// finishResult completes the result for a file with emojis from its cleaned content
+// and, unless dryRun is set or a setting holds the file back, writes that content.
+func (fp *FileProcessor) finishResult(result ProcessResult, cleanedText string, dryRun bool) (ProcessResult, error) {
+	filePath := result.FilePath
+	data, err := fp.encodeOutput(cleanedText)
+	if err != nil {
+		return result, err
+	}
+	result.NewSize = int64(len(data))
+	result.Cleaned = string(data)
+	if fp.HashCleaned {
+		sum := sha256.Sum256(data)
+		result.CleanedSHA256 = hex.EncodeToString(sum[:])
+	}
 	result.Modified = true
 
+	if fp.WarnOnEmpty || fp.RefuseEmpty {
+		result.Emptied = strings.TrimSpace(cleanedText) == ""
+	}
+	if result.Emptied && fp.RefuseEmpty && !dryRun {
+		result.WriteRefused = true
+		dryRun = true
+	}
+
 	if !dryRun {
-		if err := os.WriteFile(filePath, []byte(cleanedText), 0600); err != nil {
-			return result, fmt.Errorf("failed to write cleaned file: %w", err)
+		perm := os.FileMode(0600)
+		if info, err := os.Stat(filePath); err == nil {
+			perm = cleanedFilePerm(info.Mode(), cleanedText)
+		}
+		if fp.batch != nil {
+			fp.batch.add(pendingWrite{path: filePath, data: data, perm: perm})
+			return result, nil
 		}
-		// Explicitly set permissions to ensure they are correct regardless of umask
-		if err := os.Chmod(filePath, 0600); err != nil {
-			return result, fmt.Errorf("failed to set file permissions: %w", err)
+		if err := fp.writeFile(filePath, data, dryRun, perm); err != nil {
+			return result, err
 		}
 	}
 
 	return result, nil
 }
 
+// countOccurrences returns the number of emojis d finds in text, counting repeats, or
+// zero unless CountOccurrences is set.
+func (fp *FileProcessor) countOccurrences(d *Detector, text string) int {
+	if !fp.CountOccurrences {
+		return 0
+	}
+	return d.Count(text)
+}
+
+// firstEmojiLocation returns the first emoji d finds in text and its line and column,
+// or the zero EmojiLocation if text has no literal emojis.
+func (fp *FileProcessor) firstEmojiLocation(d *Detector, text string) EmojiLocation {
+	spans := d.EmojiSpans(text)
+	if len(spans) == 0 {
+		return EmojiLocation{}
+	}
+
+	prefix := text[:spans[0][0]]
+	lineStart := strings.LastIndexByte(prefix, '\n') + 1
+	return EmojiLocation{
+		Emoji:  text[spans[0][0]:spans[0][1]],
+		Line:   strings.Count(prefix, "\n") + 1,
+		Column: columnWidth(prefix[lineStart:], fp.ColumnMode) + 1,
+	}
+}
+
+// CleanText removes emojis from text using the processor's Detector and applies
+// any configured post-processing.
+func (fp *FileProcessor) CleanText(text string) string {
+	return fp.postProcess(text, fp.removeEmojis(fp.Detector, text))
+}
+
+// CleanAndReport is CleanText that also returns the unique emojis found in text, as
+// FindEmojis would, without scanning the text a second time when it can be avoided.
+func (fp *FileProcessor) CleanAndReport(text string) (string, []string) {
+	if fp.ASCIIFallback {
+		return fp.CleanText(text), fp.Detector.FindEmojis(text)
+	}
+	removed, emojis := fp.Detector.StripAndReport(text)
+	return fp.postProcess(text, removed), emojis
+}
+
+// postProcess applies the configured line post-processing to text whose emojis have
+// already been removed.
+func (fp *FileProcessor) postProcess(original, cleaned string) string {
+	switch {
+	case fp.DeleteEmojiOnlyLines:
+		cleaned = deleteEmojiOnlyLines(original, cleaned)
+	case fp.SqueezeBlankLines:
+		cleaned = squeezeBlankLines(original, cleaned)
+	}
+	return cleaned
+}
+
+// removeEmojis removes or replaces the emojis d finds without any line post-processing.
+func (fp *FileProcessor) removeEmojis(d *Detector, text string) string {
+	if fp.ASCIIFallback {
+		return d.ReplaceWithASCII(text)
+	}
+	return d.RemoveEmojis(text)
+}
+
+// stripAndReport removes or replaces the emojis d finds, like removeEmojis, and also
+// returns them. Removal shares one pass with detection (see Detector.StripAndReport);
+// ASCII replacement needs its own.
+func (fp *FileProcessor) stripAndReport(d *Detector, text string) (string, []string) {
+	if fp.ASCIIFallback {
+		return d.ReplaceWithASCII(text), d.FindEmojis(text)
+	}
+	return d.StripAndReport(text)
+}
+
+// emojiOnlyLines returns the 1-based numbers of lines that had content in original
+// but are blank in cleaned, i.e. lines whose only non-whitespace content was emojis.
+func emojiOnlyLines(original, cleaned string) []int {
+	originalLines := strings.Split(original, "\n")
+	cleanedLines := strings.Split(cleaned, "\n")
+	if len(originalLines) != len(cleanedLines) {
+		return nil
+	}
+
+	var lines []int
+	for i := range originalLines {
+		if strings.TrimSpace(originalLines[i]) != "" && strings.TrimSpace(cleanedLines[i]) == "" {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// deleteEmojiOnlyLines removes the lines of cleaned that emojiOnlyLines reports,
+// including their line breaks.
+func deleteEmojiOnlyLines(original, cleaned string) string {
+	lines := emojiOnlyLines(original, cleaned)
+	if len(lines) == 0 {
+		return cleaned
+	}
+
+	cleanedLines := strings.Split(cleaned, "\n")
+	lastDeleted := lines[len(lines)-1] == len(cleanedLines)
+	out := make([]string, 0, len(cleanedLines)-len(lines)+1)
+	for i, line := range cleanedLines {
+		if len(lines) > 0 && lines[0] == i+1 {
+			lines = lines[1:]
+			continue
+		}
+		out = append(out, line)
+	}
+	// A deleted final line had no line break of its own, so keep the one ending the line before it
+	if lastDeleted && len(out) > 0 {
+		out = append(out, "")
+	}
+	return strings.Join(out, "\n")
+}
+
+// squeezeBlankLines collapses runs of blank lines in cleaned that were created by
+// emoji removal. Emoji removal never touches newlines, so the lines of original and
+// cleaned line up one-to-one. Within a run of blank lines, pre-existing blank lines
+// are kept as-is; a run made up only of newly blank lines is reduced to one line.
+func squeezeBlankLines(original, cleaned string) string {
+	originalLines := strings.Split(original, "\n")
+	cleanedLines := strings.Split(cleaned, "\n")
+	if len(originalLines) != len(cleanedLines) {
+		return cleaned
+	}
+
+	isBlank := func(line string) bool {
+		return strings.TrimSpace(line) == ""
+	}
+
+	var out []string
+	for i := 0; i < len(cleanedLines); {
+		if !isBlank(cleanedLines[i]) {
+			out = append(out, cleanedLines[i])
+			i++
+			continue
+		}
+
+		// Collect the run of blank lines starting at i
+		end := i
+		hasOriginalBlank := false
+		for end < len(cleanedLines) && isBlank(cleanedLines[end]) {
+			if isBlank(originalLines[end]) {
+				hasOriginalBlank = true
+			}
+			end++
+		}
+
+		if hasOriginalBlank {
+			for j := i; j < end; j++ {
+				if isBlank(originalLines[j]) {
+					out = append(out, cleanedLines[j])
+				}
+			}
+		} else {
+			out = append(out, cleanedLines[i])
+		}
+		i = end
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// cleanedFilePerm returns the permissions a cleaned file is written with: 0600, plus
+// the original executable bits for scripts starting with a shebang so that cleaning a
+// script does not stop it from running.
+func cleanedFilePerm(original os.FileMode, cleaned string) os.FileMode {
+	perm := os.FileMode(0600)
+	if strings.HasPrefix(cleaned, "#!") {
+		perm |= original.Perm() & 0111
+	}
+	return perm
+}
+
+// writeFile is the single path through which processed files are written.
+// With AssertNoWrites set, a write attempted during a dry run is refused.
+func (fp *FileProcessor) writeFile(filePath string, data []byte, dryRun bool, perm os.FileMode) error {
+	if dryRun && fp.AssertNoWrites {
+		return fmt.Errorf("%w: %s", ErrWriteInDryRun, filePath)
+	}
+
+	if fp.AtomicWrites {
+		return writeFileAtomic(filePath, data, perm)
+	}
+
+	if err := os.WriteFile(filePath, data, perm); err != nil {
+		return fmt.Errorf("failed to write cleaned file: %w", err)
+	}
+	// Explicitly set permissions to ensure they are correct regardless of umask
+	if err := os.Chmod(filePath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces filePath with data by writing a temporary file next to
+// it and renaming it into place.
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".emoji-sad-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op once the rename has succeeded
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cleaned file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cleaned file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
+
+// isDuplicateHardlink reports whether filePath is a hard link to a file already
+// processed in this run, recording it in Deduped if so.
+func (fp *FileProcessor) isDuplicateHardlink(filePath string) bool {
+	if !fp.DedupeHardlinks {
+		return false
+	}
+
+	id, ok := hardlinkID(filePath)
+	if !ok {
+		return false
+	}
+
+	fp.seenMu.Lock()
+	defer fp.seenMu.Unlock()
+
+	if fp.seenFiles == nil {
+		fp.seenFiles = make(map[fileID]string)
+	}
+	if _, seen := fp.seenFiles[id]; seen {
+		fp.Deduped = append(fp.Deduped, filePath)
+		return true
+	}
+	fp.seenFiles[id] = filePath
+	return false
+}
+
+// shouldSkip applies shouldSkipFile, letting archives through when they are scanned
+// and any regular file through when NoSkipBinary is set.
+func (fp *FileProcessor) shouldSkip(path string) bool {
+	if fp.NoSkipBinary || fp.isScannableGzip(path) || fp.IsScannableZip(path) {
+		info, err := os.Stat(path)
+		return err != nil || !info.Mode().IsRegular()
+	}
+	return shouldSkipFile(path)
+}
+
 func shouldSkipFile(path string) bool {
 	// Check file type first
 	info, err := os.Stat(path)
@@ -146,17 +915,17 @@ func shouldSkipFile(path string) bool {
 	}
 
 	// Check file extensions
-	ext := filepath.Ext(path)
-	skipExtensions := map[string]bool{
-		".exe": true, ".bin": true, ".so": true, ".dll": true,
-		".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true,
-		".mp3": true, ".mp4": true, ".avi": true, ".mov": true,
-		".zip": true, ".tar": true, ".gz": true, ".7z": true,
-		".pdf":  true,
-		".sock": true, // Add socket extension explicitly too
-	}
+	return skipExtensions[filepath.Ext(path)]
+}
 
-	return skipExtensions[ext]
+// skipExtensions lists extensions of binary files that are never processed.
+var skipExtensions = map[string]bool{
+	".exe": true, ".bin": true, ".so": true, ".dll": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true,
+	".mp3": true, ".mp4": true, ".avi": true, ".mov": true,
+	".zip": true, ".tar": true, ".gz": true, ".7z": true,
+	".pdf":  true,
+	".sock": true, // Add socket extension explicitly too
 }
 
 // isExcluded checks if a path matches any of the exclusion patterns.
@@ -167,6 +936,21 @@ func (fp *FileProcessor) isExcluded(path string) bool {
 			return true
 		}
 	}
+	for _, re := range fp.ExcludeRegexps {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isProtected checks if a path matches any of the Protect patterns.
+func (fp *FileProcessor) isProtected(path string) bool {
+	for _, pattern := range fp.Protect {
+		if fp.matchExclude(path, pattern) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -1,12 +1,20 @@
 package emoji
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewFileProcessor(t *testing.T) {
@@ -356,6 +364,43 @@ func TestFileProcessor_ProcessFile_FilePermissions(t *testing.T) {
 	}
 }
 
+func TestFileProcessor_ProcessFile_ShebangKeepsExecutable(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected os.FileMode
+	}{
+		{"shebang script", "#!/bin/sh\necho \"Deploying 🚀\"\n", 0700},
+		{"executable without shebang", "Hello 😊", 0600},
+	}
+
+	for _, atomic := range []bool{false, true} {
+		for _, tt := range tests {
+			filePath := filepath.Join(t.TempDir(), "deploy")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(filePath, 0700); err != nil { // #nosec G302 -- test script must be executable
+				t.Fatal(err)
+			}
+
+			processor := NewFileProcessor()
+			processor.AtomicWrites = atomic
+			if _, err := processor.ProcessFile(filePath, false); err != nil {
+				t.Fatalf("ProcessFile() error = %v", err)
+			}
+
+			info, err := os.Stat(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != tt.expected {
+				t.Errorf("%s (atomic %v): permissions = %v, want %v", tt.name, atomic, perm, tt.expected)
+			}
+		}
+	}
+}
+
 func TestFileProcessor_ProcessFile_ReadError(t *testing.T) {
 	processor := NewFileProcessor()
 
@@ -674,3 +719,982 @@ func TestFileProcessor_IsExcluded(t *testing.T) {
 		})
 	}
 }
+
+func TestFileProcessor_ExcludeRegexps(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_exclude_regex_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	testFiles := map[string]string{
+		"main.txt":                "emoji 😊 in main",
+		"testdata/fixture.txt":    "emoji 🚀 in fixture",
+		"pkg/testdata/golden.txt": "emoji 🌍 in golden",
+		"pkg/code.txt":            "emoji 🎉 in code",
+	}
+	for relPath, content := range testFiles {
+		fullPath := filepath.Join(tempDir, relPath)
+		_ = os.MkdirAll(filepath.Dir(fullPath), 0750)
+		_ = os.WriteFile(fullPath, []byte(content), 0600)
+	}
+
+	processor := NewFileProcessor()
+	processor.ExcludeRegexps = []*regexp.Regexp{regexp.MustCompile(`.*/testdata/.*`)}
+
+	results, err := processor.ProcessDirectory(tempDir, true)
+	if err != nil {
+		t.Fatal("ProcessDirectory failed:", err)
+	}
+
+	var found []string
+	for _, result := range results {
+		found = append(found, filepath.Base(result.FilePath))
+	}
+	sort.Strings(found)
+
+	expected := []string{"code.txt", "main.txt"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Processed files = %v, want %v", found, expected)
+	}
+}
+
+func TestFileProcessor_AssertNoWrites(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_assert_no_writes_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	testFile := filepath.Join(tempDir, "test.txt")
+	original := "Hello 😊 world 🚀"
+	_ = os.WriteFile(testFile, []byte(original), 0644) // #nosec G306 -- permissions checked below
+
+	processor := NewFileProcessor()
+	processor.AssertNoWrites = true
+
+	t.Run("dry run processing never writes", func(t *testing.T) {
+		before, _ := os.Stat(testFile)
+
+		results, err := processor.ProcessDirectory(tempDir, true)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed with guard enabled:", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+
+		after, _ := os.Stat(testFile)
+		content, _ := os.ReadFile(testFile) // #nosec G304 -- testFile is controlled in test
+		if string(content) != original {
+			t.Errorf("File content changed during dry run: %q", string(content))
+		}
+		if !after.ModTime().Equal(before.ModTime()) || after.Mode() != before.Mode() {
+			t.Error("File metadata changed during dry run")
+		}
+	})
+
+	t.Run("guard refuses writes during dry run", func(t *testing.T) {
+		err := processor.writeFile(testFile, []byte("changed"), true, 0600)
+		if !errors.Is(err, ErrWriteInDryRun) {
+			t.Errorf("writeFile() error = %v, want ErrWriteInDryRun", err)
+		}
+		content, _ := os.ReadFile(testFile) // #nosec G304 -- testFile is controlled in test
+		if string(content) != original {
+			t.Errorf("Guarded write modified file: %q", string(content))
+		}
+	})
+
+	t.Run("guard allows writes outside dry run", func(t *testing.T) {
+		if _, err := processor.ProcessFile(testFile, false); err != nil {
+			t.Fatal("ProcessFile failed:", err)
+		}
+		content, _ := os.ReadFile(testFile) // #nosec G304 -- testFile is controlled in test
+		if string(content) != "Hello  world " {
+			t.Errorf("File content = %q, want %q", string(content), "Hello  world ")
+		}
+	})
+}
+
+func TestFileProcessor_SqueezeBlankLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		squeeze  bool
+		input    string
+		expected string
+	}{
+		{
+			name:     "disabled leaves blank lines",
+			squeeze:  false,
+			input:    "one\n🚀\n✨\ntwo",
+			expected: "one\n\n\ntwo",
+		},
+		{
+			name:     "adjacent emoji-only lines collapse to one",
+			squeeze:  true,
+			input:    "one\n🚀\n✨\n🎉\ntwo",
+			expected: "one\n\ntwo",
+		},
+		{
+			name:     "emoji-only lines interleaved with text",
+			squeeze:  true,
+			input:    "one\n🚀\ntwo\n✨\n🎉\nthree",
+			expected: "one\n\ntwo\n\nthree",
+		},
+		{
+			name:     "pre-existing blank lines are preserved",
+			squeeze:  true,
+			input:    "one\n\n\ntwo 😊\nthree",
+			expected: "one\n\n\ntwo \nthree",
+		},
+		{
+			name:     "emoji-only line next to existing blank line",
+			squeeze:  true,
+			input:    "one\n\n🚀\ntwo",
+			expected: "one\n\ntwo",
+		},
+		{
+			name:     "emoji-only line at end of file",
+			squeeze:  true,
+			input:    "one\n🚀\n",
+			expected: "one\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewFileProcessor()
+			processor.SqueezeBlankLines = tt.squeeze
+			result := processor.CleanText(tt.input)
+			if result != tt.expected {
+				t.Errorf("CleanText(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFileProcessor_DedupeHardlinks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_hardlink_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	original := filepath.Join(tempDir, "a.txt")
+	link := filepath.Join(tempDir, "b.txt")
+	_ = os.WriteFile(original, []byte("Hello 😊"), 0600)
+	if err := os.Link(original, link); err != nil {
+		t.Skip("Hard links not supported:", err)
+	}
+
+	t.Run("both links processed by default", func(t *testing.T) {
+		processor := NewFileProcessor()
+		results, err := processor.ProcessDirectory(tempDir, true)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 2 {
+			t.Errorf("Expected 2 results without dedupe, got %d", len(results))
+		}
+	})
+
+	t.Run("second link skipped with DedupeHardlinks", func(t *testing.T) {
+		processor := NewFileProcessor()
+		processor.DedupeHardlinks = true
+		results, err := processor.ProcessDirectory(tempDir, false)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result with dedupe, got %d", len(results))
+		}
+		if results[0].FilePath != original {
+			t.Errorf("Processed %s, want %s", results[0].FilePath, original)
+		}
+		if !reflect.DeepEqual(processor.Deduped, []string{link}) {
+			t.Errorf("Deduped = %v, want [%s]", processor.Deduped, link)
+		}
+	})
+}
+
+func TestFileProcessor_IncludeModTime(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_mtime_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	testFile := filepath.Join(tempDir, "test.txt")
+	_ = os.WriteFile(testFile, []byte("Hello 😊"), 0600)
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(testFile, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("not recorded by default", func(t *testing.T) {
+		processor := NewFileProcessor()
+		results, err := processor.ProcessDirectory(tempDir, true)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 1 || !results[0].ModifiedTime.IsZero() {
+			t.Errorf("Expected zero ModifiedTime, got %v", results)
+		}
+	})
+
+	t.Run("recorded with IncludeModTime", func(t *testing.T) {
+		processor := NewFileProcessor()
+		processor.IncludeModTime = true
+		results, err := processor.ProcessDirectory(tempDir, false)
+		if err != nil {
+			t.Fatal("ProcessDirectory failed:", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+		if !results[0].ModifiedTime.Equal(mtime) {
+			t.Errorf("ModifiedTime = %v, want %v", results[0].ModifiedTime, mtime)
+		}
+	})
+}
+
+func TestFileProcessor_Limit(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_limit_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"} {
+		_ = os.WriteFile(filepath.Join(tempDir, name), []byte("emoji 😊"), 0600)
+	}
+
+	processor := NewFileProcessor()
+	processor.Limit = 2
+	results, err := processor.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatal("ProcessDirectory failed:", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected exactly 2 results, got %d", len(results))
+	}
+
+	// Only the files within the limit should have been modified
+	modified := 0
+	entries, _ := os.ReadDir(tempDir)
+	for _, entry := range entries {
+		content, _ := os.ReadFile(filepath.Join(tempDir, entry.Name())) // #nosec G304 -- path is controlled in test
+		if string(content) == "emoji " {
+			modified++
+		}
+	}
+	if modified != 2 {
+		t.Errorf("Expected 2 files modified, got %d", modified)
+	}
+}
+
+func TestFileProcessor_ModifiedSince(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "emoji_since_test_")
+	if err != nil {
+		t.Fatal("Failed to create temp directory:", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	oldFile := filepath.Join(tempDir, "old.txt")
+	recentFile := filepath.Join(tempDir, "recent.txt")
+	_ = os.WriteFile(oldFile, []byte("old 😊"), 0600)
+	_ = os.WriteFile(recentFile, []byte("recent 🚀"), 0600)
+
+	oldTime := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(oldFile, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := NewFileProcessorWithExcludes([]string{"excluded.txt"})
+	processor.ModifiedSince = time.Now().Add(-168 * time.Hour)
+	_ = os.WriteFile(filepath.Join(tempDir, "excluded.txt"), []byte("recent but excluded 🎉"), 0600)
+
+	results, err := processor.ProcessDirectory(tempDir, true)
+	if err != nil {
+		t.Fatal("ProcessDirectory failed:", err)
+	}
+	if len(results) != 1 || results[0].FilePath != recentFile {
+		t.Fatalf("Expected only %s, got %v", recentFile, results)
+	}
+	if !results[0].ModifiedTime.IsZero() {
+		t.Error("ModifiedTime should only be recorded with IncludeModTime")
+	}
+}
+
+func TestFileProcessor_ASCIIFallback(t *testing.T) {
+	processor := NewFileProcessor()
+	processor.ASCIIFallback = true
+
+	if result := processor.CleanText("Nice 😊 launch 🚀"); result != "Nice :) launch " {
+		t.Errorf("CleanText() = %q, want %q", result, "Nice :) launch ")
+	}
+}
+
+func TestFileProcessor_CleanAndReport(t *testing.T) {
+	input := "Nice 😊 launch 🚀\n🎉\n\nend ✅"
+
+	for _, configure := range []func(*FileProcessor){
+		func(*FileProcessor) {},
+		func(fp *FileProcessor) { fp.ASCIIFallback = true },
+		func(fp *FileProcessor) { fp.SqueezeBlankLines = true },
+		func(fp *FileProcessor) { fp.DeleteEmojiOnlyLines = true },
+	} {
+		processor := NewFileProcessor()
+		configure(processor)
+
+		cleaned, found := processor.CleanAndReport(input)
+		if want := processor.CleanText(input); cleaned != want {
+			t.Errorf("CleanAndReport() cleaned = %q, want %q", cleaned, want)
+		}
+		if want := processor.Detector.FindEmojis(input); !reflect.DeepEqual(found, want) {
+			t.Errorf("CleanAndReport() found = %q, want %q", found, want)
+		}
+	}
+}
+
+func TestFileProcessor_FileSizeRange(t *testing.T) {
+	tempDir := t.TempDir()
+	sizes := map[string]int{"empty.txt": 0, "min.txt": 4, "max.txt": 8, "big.txt": 9}
+	for name, size := range sizes {
+		// Every non-empty file starts with a 4-byte emoji so it counts as a hit
+		content := strings.Repeat("x", size)
+		if size >= 4 {
+			content = "😊" + strings.Repeat("x", size-4)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := NewFileProcessor()
+	fp.MinFileSize = 4
+	fp.MaxFileSize = 8
+	results, err := fp.ProcessDirectory(tempDir, true)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	var processed []string
+	for _, result := range results {
+		processed = append(processed, filepath.Base(result.FilePath))
+	}
+	sort.Strings(processed)
+	if !reflect.DeepEqual(processed, []string{"max.txt", "min.txt"}) {
+		t.Errorf("Expected files at both bounds to be processed, got %v", processed)
+	}
+
+	skipped := make(map[string]string)
+	for _, s := range fp.Skipped {
+		skipped[filepath.Base(s.Path)] = s.Reason
+	}
+	if len(skipped) != 2 || !strings.Contains(skipped["empty.txt"], "below the minimum") || !strings.Contains(skipped["big.txt"], "above the maximum") {
+		t.Errorf("Unexpected skipped files: %v", skipped)
+	}
+}
+
+func TestFileProcessor_SkipBinaryByMIME(t *testing.T) {
+	tempDir := t.TempDir()
+	pdf := filepath.Join(tempDir, "report")
+	text := filepath.Join(tempDir, "notes")
+	_ = os.WriteFile(pdf, []byte("%PDF-1.7\n% 😊 emoji in a PDF string\n"), 0600)
+	_ = os.WriteFile(text, []byte("Plain notes 😊\n"), 0600)
+
+	fp := NewFileProcessor()
+	fp.SkipBinaryByMIME = true
+	results, err := fp.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	if len(results) != 1 || results[0].FilePath != text {
+		t.Errorf("Expected only the text file in results, got %+v", results)
+	}
+	if len(fp.Skipped) != 1 || fp.Skipped[0].Path != pdf || !strings.Contains(fp.Skipped[0].Reason, "application/pdf") {
+		t.Errorf("Expected the PDF to be skipped as application/pdf, got %+v", fp.Skipped)
+	}
+	if content, _ := os.ReadFile(pdf); !strings.Contains(string(content), "😊") {
+		t.Error("Skipped PDF should not be modified")
+	}
+	if content, _ := os.ReadFile(text); string(content) != "Plain notes \n" {
+		t.Errorf("Text file should be cleaned, got %q", content)
+	}
+}
+
+func TestFileProcessor_MaxLines(t *testing.T) {
+	tempDir := t.TempDir()
+	under := filepath.Join(tempDir, "under.txt")
+	at := filepath.Join(tempDir, "at.txt")
+	over := filepath.Join(tempDir, "over.txt")
+	_ = os.WriteFile(under, []byte("one 😊\ntwo\n"), 0600)
+	_ = os.WriteFile(at, []byte("one 😊\ntwo\nthree"), 0600)
+	_ = os.WriteFile(over, []byte("one 😊\ntwo\nthree\nfour\n"), 0600)
+
+	fp := NewFileProcessor()
+	fp.MaxLines = 3
+	results, err := fp.ProcessDirectory(tempDir, true)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	if len(results) != 2 || results[0].FilePath != at || results[1].FilePath != under {
+		t.Errorf("Expected at.txt and under.txt in results, got %+v", results)
+	}
+	if len(fp.Skipped) != 1 || fp.Skipped[0].Path != over || fp.Skipped[0].Reason != "4 lines is above the maximum of 3 lines" {
+		t.Errorf("Expected over.txt to be skipped for its 4 lines, got %+v", fp.Skipped)
+	}
+}
+
+func TestFileProcessor_SkipSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "target.txt")
+	link := filepath.Join(tempDir, "link.txt")
+	nested := filepath.Join(tempDir, "docs", "nested.txt")
+	_ = os.MkdirAll(filepath.Dir(nested), 0750)
+	_ = os.WriteFile(target, []byte("Hello 😊"), 0600)
+	_ = os.WriteFile(nested, []byte("Nested 🚀"), 0600)
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	fp := NewFileProcessor()
+	fp.SkipSymlinks = true
+	results, err := fp.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	if len(results) != 2 || results[0].FilePath != nested || results[1].FilePath != target {
+		t.Errorf("Expected nested.txt and target.txt in results, got %+v", results)
+	}
+	if len(fp.Skipped) != 1 || fp.Skipped[0].Path != link || fp.Skipped[0].Reason != "symbolic link" {
+		t.Errorf("Expected link.txt to be skipped as a symbolic link, got %+v", fp.Skipped)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected link.txt to still be a symbolic link, got %v, %v", info, err)
+	}
+}
+
+func TestFileProcessor_SkipFunc(t *testing.T) {
+	tempDir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(tempDir, "logs"), 0750)
+	appLog := filepath.Join(tempDir, "logs", "app.log")
+	rootLog := filepath.Join(tempDir, "debug.log")
+	notes := filepath.Join(tempDir, "notes.txt")
+	for _, path := range []string{appLog, rootLog, notes} {
+		_ = os.WriteFile(path, []byte("started 😊\n"), 0600)
+	}
+
+	fp := NewFileProcessor()
+	var consulted []string
+	fp.SkipFunc = func(path string, info fs.FileInfo) (bool, string) {
+		consulted = append(consulted, info.Name())
+		if filepath.Ext(path) == ".log" {
+			return true, "log files are rotated, not cleaned"
+		}
+		return false, ""
+	}
+	results, err := fp.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+
+	if len(results) != 1 || results[0].FilePath != notes {
+		t.Errorf("Expected only notes.txt in results, got %+v", results)
+	}
+	want := []SkippedFile{
+		{Path: rootLog, Reason: "log files are rotated, not cleaned"},
+		{Path: appLog, Reason: "log files are rotated, not cleaned"},
+	}
+	if !reflect.DeepEqual(fp.Skipped, want) {
+		t.Errorf("Skipped = %+v, want %+v", fp.Skipped, want)
+	}
+	if len(consulted) != 3 {
+		t.Errorf("SkipFunc should only be consulted for the 3 files, got %q", consulted)
+	}
+	for _, path := range []string{appLog, rootLog} {
+		if content, _ := os.ReadFile(path); !strings.Contains(string(content), "😊") {
+			t.Errorf("Skipped %s should not be modified", path)
+		}
+	}
+}
+
+func TestFileProcessor_RecordFirstEmoji(t *testing.T) {
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(file, []byte("first line\nnaïve ✨ then 🚀\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	result, err := fp.ProcessFile(file, true)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if result.FirstEmoji != (EmojiLocation{}) {
+		t.Errorf("Expected no location unless RecordFirstEmoji is set, got %+v", result.FirstEmoji)
+	}
+
+	fp.RecordFirstEmoji = true
+	result, err = fp.ProcessFile(file, true)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	expected := EmojiLocation{Emoji: "✨", Line: 2, Column: 7}
+	if result.FirstEmoji != expected {
+		t.Errorf("FirstEmoji = %+v, want %+v", result.FirstEmoji, expected)
+	}
+}
+
+func TestFileProcessor_ColumnMode(t *testing.T) {
+	// "日本語 " is 3 wide characters and a space: 4 runes, 10 bytes, 7 display columns.
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(file, []byte("first\n日本語 🚀 done\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode   ColumnMode
+		column int
+	}{
+		{"", 5},
+		{ColumnRune, 5},
+		{ColumnByte, 11},
+		{ColumnDisplay, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			fp := NewFileProcessor()
+			fp.RecordFirstEmoji = true
+			fp.ColumnMode = tt.mode
+
+			result, err := fp.ProcessFile(file, true)
+			if err != nil {
+				t.Fatalf("ProcessFile() error = %v", err)
+			}
+			expected := EmojiLocation{Emoji: "🚀", Line: 2, Column: tt.column}
+			if result.FirstEmoji != expected {
+				t.Errorf("FirstEmoji = %+v, want %+v", result.FirstEmoji, expected)
+			}
+		})
+	}
+}
+
+func TestParseColumnMode(t *testing.T) {
+	for _, s := range []string{"rune", "byte", "display"} {
+		mode, err := ParseColumnMode(s)
+		if err != nil || string(mode) != s {
+			t.Errorf("ParseColumnMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseColumnMode("cells"); err == nil {
+		t.Error("Expected error for invalid column mode")
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		r     rune
+		width int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'日', 2},
+		{'한', 2},
+		{'\u1100', 2}, // Hangul Jamo, before the emoji presentation symbols
+		{'\u115F', 2},
+		{'\u1160', 1},
+		{'Ａ', 2},
+		{'🚀', 2},
+		{'✅', 2},
+		{'☀', 1},
+		{'\u0301', 0},
+		{zeroWidthJoiner, 0},
+		{variationSelector16, 0},
+	}
+	for _, tt := range tests {
+		if got := runeWidth(tt.r); got != tt.width {
+			t.Errorf("runeWidth(%U) = %d, want %d", tt.r, got, tt.width)
+		}
+	}
+}
+
+func TestWideRangesSorted(t *testing.T) {
+	for i, rr := range wideRanges {
+		if rr.lo > rr.hi {
+			t.Errorf("wideRanges[%d] = %U-%U is inverted", i, rr.lo, rr.hi)
+		}
+		if i > 0 && rr.lo <= wideRanges[i-1].hi {
+			t.Errorf("wideRanges[%d] = %U-%U is out of order or overlaps %U-%U", i, rr.lo, rr.hi, wideRanges[i-1].lo, wideRanges[i-1].hi)
+		}
+	}
+}
+
+func TestFileProcessor_EmojiOnlyLines(t *testing.T) {
+	input := "title\n🚀\n  🎉 ✨  \nship it 🚀\n\nend ✅"
+
+	tests := []struct {
+		name          string
+		deleteLines   bool
+		expectedLines []int
+		expectedText  string
+	}{
+		{
+			name:          "report only",
+			expectedLines: []int{2, 3},
+			expectedText:  "title\n\n     \nship it \n\nend ",
+		},
+		{
+			name:          "delete emoji-only lines",
+			deleteLines:   true,
+			expectedLines: []int{2, 3},
+			expectedText:  "title\nship it \n\nend ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "test.txt")
+			if err := os.WriteFile(file, []byte(input), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			fp := NewFileProcessor()
+			fp.ReportEmojiOnlyLines = true
+			fp.DeleteEmojiOnlyLines = tt.deleteLines
+			result, err := fp.ProcessFile(file, false)
+			if err != nil {
+				t.Fatalf("ProcessFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.EmojiOnlyLines, tt.expectedLines) {
+				t.Errorf("EmojiOnlyLines = %v, want %v", result.EmojiOnlyLines, tt.expectedLines)
+			}
+			content, _ := os.ReadFile(file)
+			if string(content) != tt.expectedText {
+				t.Errorf("content = %q, want %q", content, tt.expectedText)
+			}
+		})
+	}
+}
+
+func TestDeleteEmojiOnlyLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"emoji-only line", "a\n🚀\nb\n", "a\nb\n"},
+		{"emoji and spaces line", "a\n  🚀 🎉 \nb", "a\nb"},
+		{"emoji and text line kept", "a\n🚀 go\nb", "a\n go\nb"},
+		{"pre-existing blank line kept", "a\n\n🚀\nb", "a\n\nb"},
+		{"final line without break", "a\n🚀", "a\n"},
+		{"only emojis", "🚀", ""},
+	}
+
+	detector := NewDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := deleteEmojiOnlyLines(tt.input, detector.RemoveEmojis(tt.input)); result != tt.expected {
+				t.Errorf("deleteEmojiOnlyLines(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProcessResult_JSONRoundTrip(t *testing.T) {
+	original := []ProcessResult{
+		{
+			FilePath:       "docs/README.md",
+			EmojisFound:    []string{"🚀", "✨"},
+			OriginalSize:   120,
+			NewSize:        112,
+			Modified:       true,
+			ModifiedTime:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			FirstEmoji:     EmojiLocation{Emoji: "🚀", Line: 3, Column: 7},
+			EmojiOnlyLines: []int{5},
+		},
+		{FilePath: "zip://release.zip!notes.txt", EmojisFound: []string{"🎉"}, OriginalSize: 40},
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"file_path":"docs/README.md"`) || !strings.Contains(string(data), `"emoji_only_lines":[5]`) {
+		t.Errorf("Unexpected JSON field names: %s", data)
+	}
+
+	var decoded []ProcessResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, original) {
+		t.Errorf("Round trip = %+v, want %+v", decoded, original)
+	}
+}
+
+func TestFileProcessor_HashCleaned(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(file, []byte("Hello 😊 World 🚀"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.HashCleaned = true
+	result, err := fp.ProcessFile(file, true)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("Hello  World "))
+	if expected := hex.EncodeToString(sum[:]); result.CleanedSHA256 != expected {
+		t.Errorf("CleanedSHA256 = %q, want %q", result.CleanedSHA256, expected)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "Hello 😊 World 🚀" {
+		t.Errorf("Dry run modified the file: %q", content)
+	}
+
+	fp.HashCleaned = false
+	if result, _ := fp.ProcessFile(file, true); result.CleanedSHA256 != "" {
+		t.Errorf("Expected no hash unless HashCleaned is set, got %q", result.CleanedSHA256)
+	}
+}
+
+func TestFileProcessor_NoSkipBinary(t *testing.T) {
+	tempDir := t.TempDir()
+	textBin := filepath.Join(tempDir, "notes.bin")
+	realBin := filepath.Join(tempDir, "blob.bin")
+	if err := os.WriteFile(textBin, []byte("Hello 😊"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(realBin, []byte{0xff, 0xfe, 0xf0, 0x9f, 0x98, 0x8a, 0x00}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	results, err := fp.ProcessDirectory(tempDir, false)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Expected .bin files to be skipped by default, got %v (err %v)", results, err)
+	}
+
+	fp.NoSkipBinary = true
+	results, err = fp.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+	if len(results) != 1 || results[0].FilePath != textBin {
+		t.Errorf("Expected only the text .bin file to be processed, got %v", results)
+	}
+	if content, _ := os.ReadFile(textBin); string(content) != "Hello " {
+		t.Errorf("Expected emoji removed from the text .bin file, got %q", content)
+	}
+	if content, _ := os.ReadFile(realBin); len(content) != 7 {
+		t.Errorf("Expected invalid UTF-8 file to be left alone, got %v", content)
+	}
+}
+
+func TestFileProcessor_AtomicWrites(t *testing.T) {
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(file, []byte("Hello 😊 World"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reader that opened the file before the rewrite keeps seeing the original
+	// content, because the file is replaced rather than truncated.
+	reader, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	fp := NewFileProcessor()
+	fp.AtomicWrites = true
+	if _, err := fp.ProcessFile(file, false); err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+
+	original, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(original) != "Hello 😊 World" {
+		t.Errorf("Open reader saw %q, want the original content", original)
+	}
+	if content, _ := os.ReadFile(file); string(content) != "Hello  World" {
+		t.Errorf("Cleaned content = %q", content)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the cleaned file to remain, got %d entries", len(entries))
+	}
+}
+
+func TestFileProcessor_CountOccurrences(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(file, []byte("🚀 and 🚀 then 😊"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	result, err := fp.ProcessFile(file, true)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if result.Occurrences != 0 {
+		t.Errorf("Expected no count unless CountOccurrences is set, got %d", result.Occurrences)
+	}
+
+	fp.CountOccurrences = true
+	result, err = fp.ProcessFile(file, true)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if result.Occurrences != 3 || len(result.EmojisFound) != 2 {
+		t.Errorf("Occurrences = %d with %d unique emojis, want 3 and 2", result.Occurrences, len(result.EmojisFound))
+	}
+}
+
+func TestFileProcessor_RefuseEmpty(t *testing.T) {
+	tempDir := t.TempDir()
+	allEmoji := filepath.Join(tempDir, "reactions.txt")
+	if err := os.WriteFile(allEmoji, []byte("🎉\n \t🚀\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.WarnOnEmpty = true
+	result, err := fp.ProcessFile(allEmoji, true)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if !result.Emptied || result.WriteRefused {
+		t.Errorf("WarnOnEmpty: Emptied = %v, WriteRefused = %v, want true, false", result.Emptied, result.WriteRefused)
+	}
+
+	fp = NewFileProcessor()
+	fp.RefuseEmpty = true
+	result, err = fp.ProcessFile(allEmoji, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if !result.Emptied || !result.WriteRefused {
+		t.Errorf("RefuseEmpty: Emptied = %v, WriteRefused = %v, want true, true", result.Emptied, result.WriteRefused)
+	}
+	if content, _ := os.ReadFile(allEmoji); string(content) != "🎉\n \t🚀\n" {
+		t.Errorf("File was written despite RefuseEmpty: %q", content)
+	}
+}
+
+func TestFileProcessor_Protect(t *testing.T) {
+	tempDir := t.TempDir()
+	protected := filepath.Join(tempDir, "secrets", "keys.md")
+	regular := filepath.Join(tempDir, "notes.md")
+	if err := os.MkdirAll(filepath.Dir(protected), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{protected, regular} {
+		if err := os.WriteFile(file, []byte("Hello 😊"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp := NewFileProcessor()
+	fp.Protect = []string{"secrets"}
+	results, err := fp.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected both files reported, got %d results", len(results))
+	}
+
+	for _, result := range results {
+		if wantProtected := result.FilePath == protected; result.Protected != wantProtected {
+			t.Errorf("%s: Protected = %v, want %v", result.FilePath, result.Protected, wantProtected)
+		}
+		if !result.Modified || result.NewSize != int64(len("Hello ")) {
+			t.Errorf("%s: expected the would-be change to be reported, got %+v", result.FilePath, result)
+		}
+	}
+
+	if content, _ := os.ReadFile(protected); string(content) != "Hello 😊" {
+		t.Errorf("Protected file was written: %q", content)
+	}
+	if content, _ := os.ReadFile(regular); string(content) != "Hello " {
+		t.Errorf("Regular file was not cleaned: %q", content)
+	}
+}
+
+func TestFileProcessor_ProcessString(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		setup       func(fp *FileProcessor)
+		wantCleaned string
+	}{
+		{"emojis", "notes.txt", "Hello 😊 World 🚀\n", nil, "Hello  World \n"},
+		{"clean", "notes.txt", "Nothing to see here\n", nil, "Nothing to see here\n"},
+		{"markdown keeps code", "README.md", "Done ✅\n\n```\nok ✅\n```\n", func(fp *FileProcessor) { fp.MarkdownAware = true }, "Done \n\n```\nok ✅\n```\n"},
+		{"refused write", "empty.txt", "🎉\n", func(fp *FileProcessor) { fp.RefuseEmpty = true }, "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := NewFileProcessor()
+			fp.CountOccurrences = true
+			fp.HashCleaned = true
+			if tt.setup != nil {
+				tt.setup(fp)
+			}
+
+			result, err := fp.ProcessString(tt.file, tt.content, false)
+			if err != nil {
+				t.Fatalf("ProcessString() error = %v", err)
+			}
+			if result.Cleaned != tt.wantCleaned {
+				t.Errorf("Cleaned = %q, want %q", result.Cleaned, tt.wantCleaned)
+			}
+
+			// The file it mirrors is left unwritten by the same settings, so the results match
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			fromFile, err := fp.ProcessFile(path, false)
+			if err != nil {
+				t.Fatalf("ProcessFile() error = %v", err)
+			}
+			fromFile.FilePath = tt.file
+			fromFile.Cleaned = result.Cleaned
+			if !reflect.DeepEqual(result, fromFile) {
+				t.Errorf("ProcessString() = %+v, ProcessFile() = %+v", result, fromFile)
+			}
+			if written, _ := os.ReadFile(path); !result.WriteRefused && string(written) != tt.wantCleaned {
+				t.Errorf("ProcessFile() wrote %q, want %q", written, tt.wantCleaned)
+			}
+		})
+	}
+
+	t.Run("nothing is written", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "served.txt")
+		if _, err := NewFileProcessor().ProcessString(name, "Hi 😊", false); err != nil {
+			t.Fatalf("ProcessString() error = %v", err)
+		}
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be created, got %v", name, err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package emoji
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RuneRange is an inclusive range of code points. Ranges are comparable, so range lists
+// can be compared with == element by element or with slices.Equal.
+type RuneRange struct {
+	Lo, Hi rune
+}
+
+// String formats the range as U+XXXX-U+YYYY.
+func (r RuneRange) String() string {
+	return fmt.Sprintf("U+%04X-U+%04X", r.Lo, r.Hi)
+}
+
+// Ranges returns the code point ranges the Detector treats as emojis, in ascending order.
+func (d *Detector) Ranges() []RuneRange {
+	ranges := make([]RuneRange, 0, len(d.ranges))
+	for _, rr := range d.ranges {
+		ranges = append(ranges, RuneRange{Lo: rr.lo, Hi: rr.hi})
+	}
+	return ranges
+}
+
+// EqualRanges reports whether d and other treat the same code points as emojis, so
+// detector configurations can be compared in tests or diffed. Other settings, such as
+// the allow list, are not compared.
+func (d *Detector) EqualRanges(other *Detector) bool {
+	return slices.Equal(d.Ranges(), other.Ranges())
+}
+
+// TagRange returns the range of tag characters that are removed together with the
+// emoji they follow, as in subdivision flags. They are never matched on their own.
+func TagRange() RuneRange {
+	return RuneRange{Lo: firstTagRune, Hi: cancelTagRune}
+}
@@ -0,0 +1,51 @@
+package emoji
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetector_Ranges(t *testing.T) {
+	ranges := NewDetector().Ranges()
+	if len(ranges) == 0 {
+		t.Fatal("Ranges() returned no ranges")
+	}
+	for i, r := range ranges {
+		if !isEmoji(r.Lo) || !isEmoji(r.Hi) {
+			t.Errorf("Range %s is not covered by the detector", r)
+		}
+		if i > 0 && r.Lo <= ranges[i-1].Hi {
+			t.Errorf("Range %s is not after %s", r, ranges[i-1])
+		}
+	}
+	if got := ranges[0].String(); got != "U+2600-U+27BF" {
+		t.Errorf("First range = %q, want %q", got, "U+2600-U+27BF")
+	}
+}
+
+func TestDetector_EqualRanges(t *testing.T) {
+	detector := NewDetector()
+	if !detector.EqualRanges(NewDetectorRegexOnly()) {
+		t.Error("Default and regex-only detectors should have equal ranges")
+	}
+	if !detector.EqualRanges(NewDetectorWithAllowed([]string{"✅"}).WithBMPOnly(false)) {
+		t.Error("Allow lists and disabled BMP-only mode should not affect the ranges")
+	}
+	if detector.EqualRanges(NewDetector().WithBMPOnly(true)) {
+		t.Error("BMP-only ranges should differ from the default ranges")
+	}
+
+	// Ranges are comparable values, so the list can be diffed directly
+	bmp := NewDetector().WithBMPOnly(true).Ranges()
+	for _, r := range bmp {
+		if !slices.Contains(detector.Ranges(), r) {
+			t.Errorf("BMP range %s is not a default range", r)
+		}
+	}
+}
+
+func TestTagRange(t *testing.T) {
+	if got := TagRange().String(); got != "U+E0020-U+E007F" {
+		t.Errorf("TagRange() = %q, want %q", got, "U+E0020-U+E007F")
+	}
+}
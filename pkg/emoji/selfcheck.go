@@ -0,0 +1,52 @@
+package emoji
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selfCheckCorpus holds lines of an expected emoji count, a tab and the text to count
+// in, covering both ends of every detected range and text that must not match.
+//
+//go:embed selfcheck_corpus.txt
+var selfCheckCorpus string
+
+// SelfCheckFailure is a corpus line the Detector counted differently than expected.
+type SelfCheckFailure struct {
+	Line int // 1-based line in the corpus
+	Text string
+	Want int
+	Got  int
+}
+
+// String formats the failure as "line N: want W, got G: text".
+func (f SelfCheckFailure) String() string {
+	return fmt.Sprintf("line %d: want %d, got %d: %q", f.Line, f.Want, f.Got, f.Text)
+}
+
+// SelfCheck counts the emojis in each line of the embedded golden corpus and compares
+// the counts with the expected ones, to catch accidental changes to the detected
+// ranges. It returns the number of cases run and those that failed. Settings such as
+// the allow list or BMP-only mode change the counts, so run it on a default Detector.
+func (d *Detector) SelfCheck() (int, []SelfCheckFailure) {
+	cases := 0
+	var failures []SelfCheckFailure
+	for i, line := range strings.Split(selfCheckCorpus, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		count, text, ok := strings.Cut(line, "\t")
+		want, err := strconv.Atoi(count)
+		if !ok || err != nil {
+			panic(fmt.Sprintf("emoji: malformed self-check corpus line %d: %q", i+1, line))
+		}
+
+		cases++
+		if got := d.Count(text); got != want {
+			failures = append(failures, SelfCheckFailure{Line: i + 1, Text: text, Want: want, Got: got})
+		}
+	}
+	return cases, failures
+}
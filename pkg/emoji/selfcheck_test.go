@@ -0,0 +1,28 @@
+package emoji
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetector_SelfCheck(t *testing.T) {
+	cases, failures := NewDetector().SelfCheck()
+	if cases == 0 {
+		t.Fatal("SelfCheck() ran no cases")
+	}
+	for _, failure := range failures {
+		t.Errorf("SelfCheck() failed %s", failure)
+	}
+}
+
+func TestDetector_SelfCheckCatchesRemovedRanges(t *testing.T) {
+	for i, removed := range emojiRanges {
+		detector := NewDetector()
+		detector.ranges = slices.Delete(slices.Clone(emojiRanges), i, i+1)
+		detector.emojiRegex = compileEmojiRegex(detector.ranges)
+
+		if _, failures := detector.SelfCheck(); len(failures) == 0 {
+			t.Errorf("SelfCheck() passed with range %X-%X removed", removed.lo, removed.hi)
+		}
+	}
+}
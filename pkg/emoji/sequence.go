@@ -0,0 +1,102 @@
+package emoji
+
+// zeroWidthJoiner glues emojis into a single sequence, as in 👨‍👩‍👧.
+const zeroWidthJoiner = '\u200D'
+
+// LongestEmojiRun returns the length, in grapheme clusters, of the longest contiguous
+// run of emojis in text. A cluster is an emoji with any variation selector, skin tone
+// modifier, tag sequence or zero-width-joined emojis that follow it, or a pair of
+// regional indicators forming a flag. Allowed emojis are counted too, since they still take up display width.
+func (d *Detector) LongestEmojiRun(text string) int {
+	runes := []rune(text)
+	longest, current := 0, 0
+
+	for i := 0; i < len(runes); {
+		if !d.isEmojiRune(runes[i]) {
+			current = 0
+			i++
+			continue
+		}
+		i = d.emojiClusterEnd(runes, i)
+		current++
+		longest = max(longest, current)
+	}
+
+	return longest
+}
+
+// WithGraphemeClusters makes FindEmojis report each emoji grapheme cluster, such as the
+// family 👨‍👩‍👧 or the flag 🇺🇸, as one string instead of its separate emojis, and
+// returns the Detector. Removal is not affected.
+func (d *Detector) WithGraphemeClusters(enabled bool) *Detector {
+	d.clusters = enabled
+	return d
+}
+
+// findEmojiClusters returns each unique emoji grapheme cluster in text, in first-seen
+// order, skipping clusters made up only of allowed emojis.
+func (d *Detector) findEmojiClusters(text string) []string {
+	var clusters []string
+	seen := make(map[string]bool)
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !d.isEmojiRune(runes[i]) {
+			i++
+			continue
+		}
+		end := d.emojiClusterEnd(runes, i)
+		cluster := string(runes[i:end])
+		i = end
+		if !seen[cluster] && !d.onlyAllowedEmojis(cluster) {
+			clusters = append(clusters, cluster)
+			seen[cluster] = true
+		}
+	}
+
+	return clusters
+}
+
+// onlyAllowedEmojis reports whether every emoji in s is allowed, so removal would
+// leave s unchanged.
+func (d *Detector) onlyAllowedEmojis(s string) bool {
+	for i := 0; i < len(s); {
+		size, found := d.nextEmoji(s[i:])
+		if found && !d.allowedEmojis[s[i:i+size]] {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// emojiClusterEnd returns the index just past the emoji grapheme cluster starting at runes[start].
+func (d *Detector) emojiClusterEnd(runes []rune, start int) int {
+	i := start + 1
+	if isRegionalIndicator(runes[start]) {
+		if i < len(runes) && isRegionalIndicator(runes[i]) {
+			i++
+		}
+		return i
+	}
+
+	for i < len(runes) {
+		switch r := runes[i]; {
+		case r == variationSelector16 || isSkinToneModifier(r) || isTagRune(r):
+			i++
+		case r == zeroWidthJoiner && i+1 < len(runes) && d.isEmojiRune(runes[i+1]):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+func isSkinToneModifier(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
@@ -0,0 +1,70 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetector_LongestEmojiRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"no emojis", "plain text", 0},
+		{"single emoji", "Launch 🚀 now", 1},
+		{"run of three", "Party 🎉🎊🥳 time", 3},
+		{"interleaved emoji and text", "🚀a🚀🚀b🚀 🚀🚀", 2},
+		{"longest run wins", "🎉 🎉🎉🎉🎉 🎉🎉", 4},
+		{"variation selector", "❤️❤️", 2},
+		{"skin tone modifier", "👍🏽👍", 2},
+		{"zero width joiner sequence", "👨‍👩‍👧🚀", 2},
+		{"flags", "🇨🇦🇺🇸🇫🇷", 3},
+		{"space breaks a run", "🚀 🚀", 1},
+	}
+
+	detector := NewDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := detector.LongestEmojiRun(tt.input); result != tt.expected {
+				t.Errorf("LongestEmojiRun(%q) = %d, want %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetector_WithGraphemeClusters(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		allowed   []string
+		ungrouped []string
+		grouped   []string
+	}{
+		{"zero width joiner family", "Family 👨‍👩‍👧 photo", nil, []string{"👨", "👩", "👧"}, []string{"👨‍👩‍👧"}},
+		{"family and its members", "👨‍👩‍👧 and 👨", nil, []string{"👨", "👩", "👧"}, []string{"👨‍👩‍👧", "👨"}},
+		{"flag", "Go 🇨🇦!", nil, []string{"🇨", "🇦"}, []string{"🇨🇦"}},
+		{"skin tone", "👍🏽 ok", nil, []string{"👍", "🏽"}, []string{"👍🏽"}},
+		{"variation selector", "I ❤️ Go", nil, []string{"❤"}, []string{"❤️"}},
+		{"partly allowed cluster still reported", "👨‍👩‍👧", []string{"👨", "👩"}, []string{"👧"}, []string{"👨‍👩‍👧"}},
+		{"fully allowed cluster skipped", "👨‍👩 and 🚀", []string{"👨", "👩"}, []string{"🚀"}, []string{"🚀"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetectorWithAllowed(tt.allowed)
+			if got := detector.FindEmojis(tt.input); !reflect.DeepEqual(got, tt.ungrouped) {
+				t.Errorf("FindEmojis(%q) = %q, want %q", tt.input, got, tt.ungrouped)
+			}
+
+			grouped := NewDetectorWithAllowed(tt.allowed).WithGraphemeClusters(true)
+			if got := grouped.FindEmojis(tt.input); !reflect.DeepEqual(got, tt.grouped) {
+				t.Errorf("grouped FindEmojis(%q) = %q, want %q", tt.input, got, tt.grouped)
+			}
+			if got, want := grouped.RemoveEmojis(tt.input), detector.RemoveEmojis(tt.input); got != want {
+				t.Errorf("grouped RemoveEmojis(%q) = %q, want %q", tt.input, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package emoji
+
+import "strings"
+
+// shortcodes maps emoji shortcodes, Unicode CLDR short names in lower case with
+// underscores for spaces, to the emoji they name. Each emoji is the bare code point,
+// without a variation selector, so it matches with or without one.
+var shortcodes = map[string]string{
+	"books":                          "📚",
+	"bug":                            "🐛",
+	"chart_increasing":               "📈",
+	"check_mark":                     "✔",
+	"check_mark_button":              "✅",
+	"clapping_hands":                 "👏",
+	"cloud":                          "☁",
+	"construction":                   "🚧",
+	"cross_mark":                     "❌",
+	"crying_face":                    "😢",
+	"exclamation_mark":               "❗",
+	"eyes":                           "👀",
+	"face_with_tears_of_joy":         "😂",
+	"fire":                           "🔥",
+	"gear":                           "⚙",
+	"globe_showing_americas":         "🌎",
+	"grinning_face":                  "😀",
+	"hammer_and_wrench":              "🛠",
+	"high_voltage":                   "⚡",
+	"hundred_points":                 "💯",
+	"key":                            "🔑",
+	"light_bulb":                     "💡",
+	"link":                           "🔗",
+	"lock":                           "🔒",
+	"magnifying_glass_tilted_left":   "🔍",
+	"memo":                           "📝",
+	"no_entry":                       "⛔",
+	"package":                        "📦",
+	"party_popper":                   "🎉",
+	"prohibited":                     "🚫",
+	"pushpin":                        "📌",
+	"question_mark":                  "❓",
+	"recycling_symbol":               "♻",
+	"red_heart":                      "❤",
+	"right_arrow":                    "➡",
+	"rocket":                         "🚀",
+	"smiling_face_with_smiling_eyes": "😊",
+	"snowflake":                      "❄",
+	"sparkles":                       "✨",
+	"sun":                            "☀",
+	"thinking_face":                  "🤔",
+	"thumbs_down":                    "👎",
+	"thumbs_up":                      "👍",
+	"umbrella":                       "☂",
+	"warning":                        "⚠",
+	"waving_hand":                    "👋",
+	"winking_face":                   "😉",
+	"wrench":                         "🔧",
+}
+
+// LookupShortcode returns the emoji named by a shortcode such as :check_mark:, with or
+// without the surrounding colons.
+func LookupShortcode(shortcode string) (string, bool) {
+	emoji, ok := shortcodes[strings.Trim(shortcode, ":")]
+	return emoji, ok
+}
+
+// isShortcode reports whether s is written as a shortcode, a name between colons.
+func isShortcode(s string) bool {
+	return len(s) > 2 && strings.HasPrefix(s, ":") && strings.HasSuffix(s, ":")
+}
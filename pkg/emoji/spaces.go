@@ -0,0 +1,56 @@
+package emoji
+
+import "strings"
+
+// ReplaceEmojisWithSpaces replaces each emoji with spaces instead of deleting it, so
+// the columns of the text after it do not shift, as fixed-width record formats need.
+// With width > 0 every emoji becomes that many spaces; otherwise it becomes as many
+// spaces as its display width: two for an emoji shown in emoji presentation (including
+// sequences joined with ZWJ, skin tones, variation selectors or flag pairs), one for a
+// symbol shown as text. A sequence is replaced as a whole. Allowed emojis are kept, and
+// encoded emojis (HTML entities and escapes) are left alone.
+func (d *Detector) ReplaceEmojisWithSpaces(text string, width int) string {
+	runes := []rune(text)
+	var out strings.Builder
+	out.Grow(len(text))
+
+	for i := 0; i < len(runes); {
+		if !d.isEmojiRune(runes[i]) {
+			out.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		end := d.emojiClusterEnd(runes, i)
+		cluster := runes[i:end]
+		base := string(runes[i]) + string(tagsAfter(cluster))
+		if d.allowedEmojis[string(cluster)] || d.allowedEmojis[base] {
+			out.WriteString(string(cluster))
+		} else if width > 0 {
+			out.WriteString(strings.Repeat(" ", width))
+		} else {
+			out.WriteString(strings.Repeat(" ", clusterWidth(cluster)))
+		}
+		i = end
+	}
+
+	return out.String()
+}
+
+// tagsAfter returns the tag characters directly following the first rune of cluster.
+func tagsAfter(cluster []rune) []rune {
+	end := 1
+	for end < len(cluster) && isTagRune(cluster[end]) {
+		end++
+	}
+	return cluster[1:end]
+}
+
+// clusterWidth returns the display width of an emoji grapheme cluster. Anything
+// beyond a bare symbol and its tags requests emoji presentation, which is two wide.
+func clusterWidth(cluster []rune) int {
+	if len(cluster) > 1+len(tagsAfter(cluster)) {
+		return 2
+	}
+	return runeWidth(cluster[0])
+}
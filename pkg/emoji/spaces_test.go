@@ -0,0 +1,64 @@
+package emoji
+
+import "testing"
+
+func TestDetector_ReplaceEmojisWithSpaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		expected string
+		// perRune is set when the input has no multi-rune sequences, so its display
+		// width can be checked by summing rune widths
+		perRune bool
+	}{
+		{"double-width emoji", "|😊|abc", 0, "|  |abc", true},
+		{"single-width symbol", "|☀|abc", 0, "| |abc", true},
+		{"wide dingbat", "|✅|abc", 0, "|  |abc", true},
+		{"variation selector requests emoji presentation", "|❤️|abc", 0, "|  |abc", false},
+		{"ZWJ sequence is one cluster", "|👨\u200D👩\u200D👧|abc", 0, "|  |abc", false},
+		{"skin tone", "|👍🏽|abc", 0, "|  |abc", false},
+		{"flag pair", "|🇺🇸|abc", 0, "|  |abc", false},
+		{"fixed width", "|😊|☀|", 3, "|   |   |", false},
+		{"no emojis", "plain text", 0, "plain text", true},
+	}
+
+	detector := NewDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detector.ReplaceEmojisWithSpaces(tt.input, tt.width)
+			if result != tt.expected {
+				t.Errorf("ReplaceEmojisWithSpaces(%q, %d) = %q, want %q", tt.input, tt.width, result, tt.expected)
+			}
+			if tt.perRune && tt.width == 0 && columnWidth(result, ColumnDisplay) != columnWidth(tt.input, ColumnDisplay) {
+				t.Errorf("Display width changed from %d to %d", columnWidth(tt.input, ColumnDisplay), columnWidth(result, ColumnDisplay))
+			}
+		})
+	}
+}
+
+func TestDetector_ReplaceEmojisWithSpacesKeepsAlignment(t *testing.T) {
+	// Fixed-width records whose third field starts at display column 12
+	records := []string{
+		"id=1 😊   | ok",
+		"id=2 ☀    | ok",
+		"id=3 ab   | ok",
+	}
+
+	detector := NewDetector()
+	for _, record := range records {
+		cleaned := detector.ReplaceEmojisWithSpaces(record, 0)
+		before := columnWidth(record[:len(record)-len("| ok")], ColumnDisplay)
+		after := columnWidth(cleaned[:len(cleaned)-len("| ok")], ColumnDisplay)
+		if before != after {
+			t.Errorf("%q: separator moved from column %d to %d", record, before, after)
+		}
+	}
+}
+
+func TestDetector_ReplaceEmojisWithSpacesAllowed(t *testing.T) {
+	detector := NewDetectorWithAllowed([]string{"✅"})
+	if result := detector.ReplaceEmojisWithSpaces("✅ 😊 ✅", 0); result != "✅    ✅" {
+		t.Errorf("ReplaceEmojisWithSpaces() = %q", result)
+	}
+}
@@ -0,0 +1,145 @@
+package emoji
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProcessDirectoryStaged removes emojis from every file in dirPath as a single unit.
+// The cleaned tree is built in a staging directory next to dirPath and swapped into
+// place only once every file has been processed. If anything fails, dirPath is left
+// exactly as it was and the staging directory is removed.
+func (fp *FileProcessor) ProcessDirectoryStaged(dirPath string) ([]ProcessResult, error) {
+	// Scan the original tree first so excludes and filters see the real paths
+	results, err := fp.ProcessDirectory(dirPath, true)
+	if err != nil {
+		return results, err
+	}
+
+	// Absolute paths keep the staging directory out of the tree when dirPath is "."
+	dirPath, err = filepath.Abs(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]bool, len(results))
+	for _, result := range results {
+		if result.Modified && !result.Protected && !result.WriteRefused {
+			path, err := filepath.Abs(result.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			changed[path] = true
+		}
+	}
+
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(dirPath), "."+filepath.Base(dirPath)+".staged-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(staging) // No-op once the staging directory has been swapped in
+	}()
+
+	if err := fp.stageTree(dirPath, staging, changed); err != nil {
+		return nil, fmt.Errorf("failed to stage %s: %w", dirPath, err)
+	}
+	if err := os.Chmod(staging, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to set staging directory permissions: %w", err)
+	}
+
+	if err := swapDirectory(dirPath, staging); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// stageTree copies src into dst, writing cleaned content for the files in changed.
+// Both are absolute paths, and dst itself is skipped should it lie inside src.
+// Unchanged files keep their permissions and modification times.
+func (fp *FileProcessor) stageTree(src, dst string, changed map[string]bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dst {
+			return fs.SkipDir
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			return fmt.Errorf("unsupported file type: %s", path)
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 -- path comes from walking the user-provided directory
+		if err != nil {
+			return err
+		}
+		if changed[path] {
+			cleaned, err := fp.CleanFileContent(path, content)
+			if err != nil {
+				return fmt.Errorf("failed to clean %s: %w", path, err)
+			}
+			return fp.writeFile(target, cleaned, false, cleanedFilePerm(info.Mode(), string(cleaned)))
+		}
+
+		if err := os.WriteFile(target, content, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// swapDirectory replaces dirPath, an absolute path, with staging. If the swap cannot be
+// completed the original directory is restored. A working directory inside dirPath is
+// moved to the same place in the cleaned tree, so relative paths keep resolving.
+func swapDirectory(dirPath, staging string) error {
+	wd, _ := os.Getwd()
+	if rel, err := filepath.Rel(dirPath, wd); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		wd = ""
+	}
+
+	backup := staging + ".old"
+	if err := os.Rename(dirPath, backup); err != nil {
+		return fmt.Errorf("failed to move original directory aside: %w", err)
+	}
+	if err := os.Rename(staging, dirPath); err != nil {
+		_ = os.Rename(backup, dirPath) // Best effort restore of the original tree
+		return fmt.Errorf("failed to swap in cleaned directory: %w", err)
+	}
+	if wd != "" {
+		if err := os.Chdir(wd); err != nil {
+			return fmt.Errorf("cleaned directory is in place but the working directory could not be moved into it: %w", err)
+		}
+	}
+	if err := os.RemoveAll(backup); err != nil {
+		return fmt.Errorf("cleaned directory is in place but the original could not be removed from %s: %w", backup, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package emoji
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeStagedTree creates a small tree under parent and returns its root.
+func writeStagedTree(t *testing.T, parent string) string {
+	t.Helper()
+	root := filepath.Join(parent, "site")
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"index.txt":       "Welcome 🚀",
+		"docs/guide.txt":  "Read me ✨",
+		"docs/plain.txt":  "no emojis",
+		"docs/notes.link": "",
+	}
+	for name, content := range files {
+		if name == "docs/notes.link" {
+			if err := os.Symlink("plain.txt", filepath.Join(root, name)); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFileProcessor_ProcessDirectoryStaged(t *testing.T) {
+	parent := t.TempDir()
+	root := writeStagedTree(t, parent)
+	old := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	plain := filepath.Join(root, "docs", "plain.txt")
+	if err := os.Chtimes(plain, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	results, err := fp.ProcessDirectoryStaged(root)
+	if err != nil {
+		t.Fatalf("ProcessDirectoryStaged() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if filepath.Dir(result.FilePath) != root && filepath.Dir(result.FilePath) != filepath.Join(root, "docs") {
+			t.Errorf("Expected result paths under the original tree, got %s", result.FilePath)
+		}
+	}
+
+	expected := map[string]string{
+		"index.txt":      "Welcome ",
+		"docs/guide.txt": "Read me ",
+		"docs/plain.txt": "no emojis",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil || string(content) != want {
+			t.Errorf("%s = %q (err %v), want %q", name, content, err, want)
+		}
+	}
+
+	if link, err := os.Readlink(filepath.Join(root, "docs", "notes.link")); err != nil || link != "plain.txt" {
+		t.Errorf("Expected symlink to be preserved, got %q (err %v)", link, err)
+	}
+	if info, err := os.Stat(plain); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("Expected unchanged file to keep its modification time")
+	}
+
+	entries, _ := os.ReadDir(parent)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the swapped tree in the parent directory, got %d entries", len(entries))
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryStaged_FailureLeavesTreeIntact(t *testing.T) {
+	parent := t.TempDir()
+	root := writeStagedTree(t, parent)
+	// A .gz file that is not gzip data fails to process when gzip scanning is on
+	if err := os.WriteFile(filepath.Join(root, "docs", "broken.gz"), []byte("not gzip"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.ScanGzip = true
+	if _, err := fp.ProcessDirectoryStaged(root); err == nil {
+		t.Fatal("Expected an error from the broken archive")
+	}
+
+	expected := map[string]string{
+		"index.txt":       "Welcome 🚀",
+		"docs/guide.txt":  "Read me ✨",
+		"docs/plain.txt":  "no emojis",
+		"docs/broken.gz":  "not gzip",
+		"docs/notes.link": "no emojis",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil || string(content) != want {
+			t.Errorf("%s = %q (err %v), want %q", name, content, err, want)
+		}
+	}
+
+	entries, _ := os.ReadDir(parent)
+	if len(entries) != 1 {
+		t.Errorf("Expected no staging directories left behind, got %d entries", len(entries))
+	}
+}
+
+func TestFileProcessor_ProcessDirectoryStaged_FromInsideTarget(t *testing.T) {
+	parent := t.TempDir()
+	root := writeStagedTree(t, parent)
+
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	fp := NewFileProcessor()
+	results, err := fp.ProcessDirectoryStaged(".")
+	if err != nil {
+		t.Fatalf("ProcessDirectoryStaged() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	// The working directory follows the swap, so relative paths see the cleaned tree
+	for name, want := range map[string]string{"index.txt": "Welcome ", "docs/guide.txt": "Read me "} {
+		content, err := os.ReadFile(name)
+		if err != nil || string(content) != want {
+			t.Errorf("%s = %q (err %v), want %q", name, content, err, want)
+		}
+	}
+
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 2 {
+		t.Errorf("Expected no staging directory inside the tree, got %d entries", len(entries))
+	}
+	if entries, _ := os.ReadDir(parent); len(entries) != 1 {
+		t.Errorf("Expected only the swapped tree in the parent directory, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,215 @@
+package emoji
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StructuredFormat is a config file format whose string values can be cleaned
+// without touching keys or structure.
+type StructuredFormat string
+
+const (
+	// StructuredJSON cleans .json files.
+	StructuredJSON StructuredFormat = "json"
+	// StructuredYAML cleans .yaml and .yml files.
+	StructuredYAML StructuredFormat = "yaml"
+)
+
+// ParseStructuredFormat converts a string to a StructuredFormat.
+func ParseStructuredFormat(s string) (StructuredFormat, error) {
+	switch format := StructuredFormat(s); format {
+	case StructuredJSON, StructuredYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid structured format: %s (must be 'yaml' or 'json')", s)
+}
+
+// Matches reports whether path has an extension of this format. The zero value
+// matches nothing.
+func (f StructuredFormat) Matches(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return f == StructuredJSON
+	case ".yaml", ".yml":
+		return f == StructuredYAML
+	}
+	return false
+}
+
+// processStructured is ProcessFile for a file in the Structured format. Only emojis
+// in string values are reported and removed. A file that does not parse is recorded
+// in Skipped and left alone.
+func (fp *FileProcessor) processStructured(d *Detector, filePath, text string, dryRun bool) (ProcessResult, error) {
+	result := ProcessResult{
+		FilePath:     filePath,
+		OriginalSize: int64(len(text)),
+		Protected:    fp.isProtected(filePath),
+	}
+	if result.Protected {
+		dryRun = true
+	}
+
+	clean := func(value string) string {
+		return fp.removeEmojis(d, value)
+	}
+	var cleaned string
+	var values []string
+	var err error
+	switch fp.Structured {
+	case StructuredJSON:
+		cleaned, values, err = cleanJSONValues(text, clean)
+	case StructuredYAML:
+		cleaned, values, err = cleanYAMLValues(text, clean)
+	}
+	if err != nil {
+		fp.recordSkipped(filePath, fmt.Sprintf("not valid %s: %v", strings.ToUpper(string(fp.Structured)), err))
+		return result, nil
+	}
+
+	// Values are joined on newlines so no emoji sequence spans two of them
+	joined := strings.Join(values, "\n")
+	result.EmojisFound = d.FindEmojis(joined)
+	if len(result.EmojisFound) == 0 {
+		return result, nil
+	}
+	result.Occurrences = fp.countOccurrences(d, joined)
+	result.Density = fp.density(d, joined)
+	result.Replacements = d.Substitutions(joined, fp.ASCIIFallback)
+
+	return fp.finishResult(result, cleaned, dryRun)
+}
+
+// cleanJSONValues applies clean to the contents of every string value in a JSON
+// document and returns the result with the original formatting and key order, along
+// with the values that were cleaned. Object keys are left unchanged.
+func cleanJSONValues(text string, clean func(string) string) (string, []string, error) {
+	if !json.Valid([]byte(text)) {
+		return "", nil, errors.New("syntax error")
+	}
+
+	var out strings.Builder
+	out.Grow(len(text))
+	var values []string
+	for i := 0; i < len(text); {
+		if text[i] != '"' {
+			out.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		end := jsonStringEnd(text, i)
+		if isJSONKey(text[end:]) {
+			out.WriteString(text[i:end])
+		} else {
+			// Emojis are never part of an escape sequence, so the raw contents
+			// can be cleaned without decoding them
+			value := text[i+1 : end-1]
+			values = append(values, value)
+			out.WriteByte('"')
+			out.WriteString(clean(value))
+			out.WriteByte('"')
+		}
+		i = end
+	}
+
+	cleaned := out.String()
+	if !json.Valid([]byte(cleaned)) {
+		return "", nil, errors.New("replacement text would break the document")
+	}
+	return cleaned, values, nil
+}
+
+// jsonStringEnd returns the index just past the JSON string literal that starts
+// with the quote at text[start].
+func jsonStringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case '"':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// isJSONKey reports whether a string literal followed by rest is an object key.
+func isJSONKey(rest string) bool {
+	rest = strings.TrimLeft(rest, " \t\r\n")
+	return strings.HasPrefix(rest, ":")
+}
+
+// cleanYAMLValues applies clean to every string scalar in value position in a YAML
+// stream and re-encodes it, along with the values that were cleaned. Mapping keys
+// are left unchanged. Key order and comments survive, but the encoder normalizes
+// indentation and quoting and may escape emojis in keys, so an unchanged stream is
+// returned as-is.
+func cleanYAMLValues(text string, clean func(string) string) (string, []string, error) {
+	var docs []*yaml.Node
+	decoder := yaml.NewDecoder(strings.NewReader(text))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	var values []string
+	changed := false
+	for _, doc := range docs {
+		walkYAMLValues(doc, func(node *yaml.Node) {
+			values = append(values, node.Value)
+			if cleaned := clean(node.Value); cleaned != node.Value {
+				node.Value = cleaned
+				changed = true
+			}
+		})
+	}
+	if !changed {
+		return text, values, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), values, nil
+}
+
+// walkYAMLValues calls fn for each string scalar under node that is not a mapping key.
+func walkYAMLValues(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkYAMLValues(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			walkYAMLValues(node.Content[i], fn)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" {
+			fn(node)
+		}
+	}
+}
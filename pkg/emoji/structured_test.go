@@ -0,0 +1,150 @@
+package emoji
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFileProcessor_StructuredJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "config.json")
+	input := `{
+  "🚀launch": "Ready 🚀 to go",
+  "name":   "plain",
+  "tags": ["ok ✅", "\"quoted\" 😊", 3, true],
+  "nested": {"🎉": {"message": "Party 🎉"}}
+}
+`
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.Structured = StructuredJSON
+	result, err := fp.ProcessFile(path, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if want := []string{"🚀", "✅", "😊", "🎉"}; !reflect.DeepEqual(result.EmojisFound, want) {
+		t.Errorf("EmojisFound = %q, want %q", result.EmojisFound, want)
+	}
+
+	want := `{
+  "🚀launch": "Ready  to go",
+  "name":   "plain",
+  "tags": ["ok ", "\"quoted\" ", 3, true],
+  "nested": {"🎉": {"message": "Party "}}
+}
+`
+	if content, _ := os.ReadFile(path); string(content) != want {
+		t.Errorf("Cleaned JSON = %s, want %s", content, want)
+	}
+}
+
+func TestFileProcessor_StructuredYAML(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "config.yaml")
+	input := `# Release settings
+🚀launch: Ready 🚀 to go
+name: plain
+tags:
+  - ok ✅
+  - "quoted 😊"
+nested:
+  🎉:
+    message: Party 🎉
+count: 3
+`
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.Structured = StructuredYAML
+	result, err := fp.ProcessFile(path, false)
+	if err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+	if want := []string{"🚀", "✅", "😊", "🎉"}; !reflect.DeepEqual(result.EmojisFound, want) {
+		t.Errorf("EmojisFound = %q, want %q", result.EmojisFound, want)
+	}
+
+	content, _ := os.ReadFile(path)
+	var parsed map[string]any
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		t.Fatalf("Cleaned YAML does not parse: %v\n%s", err, content)
+	}
+	want := map[string]any{
+		"🚀launch": "Ready  to go",
+		"name":    "plain",
+		"tags":    []any{"ok ", "quoted "},
+		"nested":  map[string]any{"🎉": map[string]any{"message": "Party "}},
+		"count":   3,
+	}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("Cleaned YAML = %v, want %v", parsed, want)
+	}
+
+	// The encoder may escape keys, but comments and key order survive
+	cleaned := string(content)
+	if !strings.HasPrefix(cleaned, "# Release settings\n") {
+		t.Errorf("Comment was not preserved:\n%s", cleaned)
+	}
+	if strings.Index(cleaned, "launch") > strings.Index(cleaned, "name:") || strings.Index(cleaned, "nested:") > strings.Index(cleaned, "count:") {
+		t.Errorf("Key order was not preserved:\n%s", cleaned)
+	}
+}
+
+func TestFileProcessor_StructuredSkipsInvalid(t *testing.T) {
+	tempDir := t.TempDir()
+	broken := filepath.Join(tempDir, "broken.json")
+	if err := os.WriteFile(broken, []byte(`{"message": "Hi 😊",`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(tempDir, "notes.md")
+	if err := os.WriteFile(other, []byte("Hi 😊"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := NewFileProcessor()
+	fp.Structured = StructuredJSON
+	results, err := fp.ProcessDirectory(tempDir, false)
+	if err != nil {
+		t.Fatalf("ProcessDirectory() error = %v", err)
+	}
+	if len(results) != 1 || results[0].FilePath != other {
+		t.Errorf("Expected only %s processed, got %+v", other, results)
+	}
+	if len(fp.Skipped) != 1 || fp.Skipped[0].Path != broken || !strings.Contains(fp.Skipped[0].Reason, "not valid JSON") {
+		t.Errorf("Expected %s skipped as invalid JSON, got %+v", broken, fp.Skipped)
+	}
+	if content, _ := os.ReadFile(broken); string(content) != `{"message": "Hi 😊",` {
+		t.Errorf("Invalid file was modified: %q", content)
+	}
+}
+
+func TestStructuredFormat_Matches(t *testing.T) {
+	tests := []struct {
+		format   StructuredFormat
+		path     string
+		expected bool
+	}{
+		{StructuredJSON, "a/config.json", true},
+		{StructuredJSON, "config.JSON", true},
+		{StructuredJSON, "config.yaml", false},
+		{StructuredYAML, "config.yaml", true},
+		{StructuredYAML, "config.yml", true},
+		{StructuredYAML, "config.json", false},
+		{"", "config.json", false},
+	}
+	for _, tt := range tests {
+		if got := tt.format.Matches(tt.path); got != tt.expected {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.format, tt.path, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package emoji
+
+import "unicode/utf8"
+
+// Emoji tag sequences, such as the subdivision flag 🏴󠁧󠁢󠁳󠁣󠁴󠁿, are a base emoji followed by tag
+// characters (U+E0020-U+E007E) spelling out the subdivision and a cancel tag (U+E007F).
+// Tag characters are invisible on their own, so they are always found and removed
+// together with their base.
+const (
+	firstTagRune  = 0xE0020
+	cancelTagRune = 0xE007F
+)
+
+// tagSequencePattern is the regex suffix matching the tag characters after a base emoji.
+const tagSequencePattern = `(?:[\x{E0020}-\x{E007E}]*\x{E007F}|[\x{E0020}-\x{E007E}]+)?`
+
+func isTagRune(r rune) bool {
+	return r >= firstTagRune && r <= cancelTagRune
+}
+
+// tagSequenceLen returns the byte length of the tag characters at the start of s, up to
+// and including the cancel tag. An unterminated run is counted too, so removing its base
+// never leaves orphaned tag characters behind.
+func tagSequenceLen(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !isTagRune(r) {
+			break
+		}
+		n += size
+		if r == cancelTagRune {
+			break
+		}
+	}
+	return n
+}
+
+// nextEmoji decodes the first character of s and reports whether it is an emoji. The
+// returned byte length covers the character plus, for an emoji, any tag sequence after it,
+// or the whole of an allowed multi-rune sequence starting with it.
+func (d *Detector) nextEmoji(s string) (int, bool) {
+	r, size := utf8.DecodeRuneInString(s)
+	if !d.isEmojiRune(r) {
+		return size, false
+	}
+	if n := d.allowedSequenceLen(r, s); n > 0 {
+		return n, true
+	}
+	return size + tagSequenceLen(s[size:]), true
+}
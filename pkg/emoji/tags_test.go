@@ -0,0 +1,62 @@
+package emoji
+
+import (
+	"strings"
+	"testing"
+)
+
+// scotland is the subdivision flag for Scotland: a black flag followed by the tags
+// "gbsct" and a cancel tag.
+const scotland = "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F"
+
+func hasTagRunes(s string) bool {
+	return strings.ContainsFunc(s, isTagRune)
+}
+
+func TestDetector_TagSequences(t *testing.T) {
+	detector := NewDetector()
+	text := "Visit " + scotland + " soon 🚀"
+
+	emojis := detector.FindEmojis(text)
+	if len(emojis) != 2 || emojis[0] != scotland || emojis[1] != "🚀" {
+		t.Errorf("FindEmojis() = %q, want the full flag sequence and 🚀", emojis)
+	}
+
+	if counts := detector.FindEmojiSet(text); counts[scotland] != 1 || counts["🏴"] != 0 {
+		t.Errorf("FindEmojiSet() = %v, want the full flag sequence counted once", counts)
+	}
+
+	if spans := detector.EmojiSpans(text); len(spans) != 2 || text[spans[0][0]:spans[0][1]] != scotland {
+		t.Errorf("EmojiSpans() = %v, want the first span to cover the full flag sequence", spans)
+	}
+
+	tests := []struct {
+		name     string
+		detector *Detector
+		input    string
+		expected string
+	}{
+		{"removed with base", NewDetector(), text, "Visit  soon "},
+		{"collapsing spaces", NewDetector().WithWhitespacePolicy(WhitespaceCollapseBoth), text, "Visit soon"},
+		{"unterminated tags", NewDetector(), "a🏴\U000E0067\U000E0062b", "ab"},
+		{"allowed sequence kept", NewDetectorWithAllowed([]string{scotland}), text, "Visit " + scotland + " soon "},
+		{"replaced sequence", NewDetector().WithReplacements(map[string]string{scotland: "[scotland]"}), text, "Visit [scotland] soon "},
+		{"orphaned tags without a base are not emojis", NewDetector(), "x\U000E0067y", "x\U000E0067y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.detector.RemoveEmojis(tt.input)
+			if result != tt.expected {
+				t.Errorf("RemoveEmojis(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	if result := detector.ReplaceWithASCII(text); hasTagRunes(result) {
+		t.Errorf("ReplaceWithASCII() left orphaned tag characters: %q", result)
+	}
+	if run := detector.LongestEmojiRun(scotland + scotland); run != 2 {
+		t.Errorf("LongestEmojiRun() = %d, want 2", run)
+	}
+}
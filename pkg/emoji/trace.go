@@ -0,0 +1,72 @@
+package emoji
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithTrace makes FindEmojis and StripAndReport write a line to w for each non-ASCII
+// rune they consider, giving its code point and the rule that decided whether it is an
+// emoji, and returns the Detector. It is meant for debugging the range table; a nil
+// writer disables tracing.
+func (d *Detector) WithTrace(w io.Writer) *Detector {
+	d.trace = w
+	return d
+}
+
+// traceText writes the detection decision for every non-ASCII rune in text to the
+// trace writer. Lines for one text are written at once so concurrent callers do not
+// interleave within a text.
+func (d *Detector) traceText(text string) {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r < utf8.RuneSelf {
+			i += size
+			continue
+		}
+
+		emojiSize, found := d.nextEmoji(text[i:])
+		switch {
+		case found:
+			emoji := text[i : i+emojiSize]
+			rr, _ := d.findEmojiRange(r)
+			tags := tagSequenceLen(text[i+size:])
+			decision := "emoji"
+			switch {
+			case emojiSize > size+tags:
+				decision, tags = "allowed sequence", 0
+			case d.allowedEmojis[emoji]:
+				decision = "allowed emoji"
+			}
+			_, _ = fmt.Fprintf(&b, "trace: U+%04X %q %s (range U+%04X-U+%04X", r, emoji, decision, rr.lo, rr.hi)
+			if tags > 0 {
+				_, _ = fmt.Fprintf(&b, ", %d-byte tag sequence", tags)
+			}
+			_, _ = fmt.Fprintln(&b, ")")
+		case r == utf8.RuneError && size == 1:
+			_, _ = fmt.Fprintf(&b, "trace: byte 0x%02X not an emoji (invalid UTF-8)\n", text[i])
+		case r < minEmojiRune:
+			_, _ = fmt.Fprintf(&b, "trace: U+%04X %q not an emoji (below U+%04X, the lowest range)\n", r, string(r), minEmojiRune)
+		default:
+			_, _ = fmt.Fprintf(&b, "trace: U+%04X %q not an emoji (no range matches)\n", r, string(r))
+		}
+		i += emojiSize
+	}
+
+	if b.Len() > 0 {
+		_, _ = io.WriteString(d.trace, b.String())
+	}
+}
+
+// findEmojiRange returns the entry of the Detector's ranges that covers r, if any.
+func (d *Detector) findEmojiRange(r rune) (runeRange, bool) {
+	for _, rr := range d.ranges {
+		if r >= rr.lo && r <= rr.hi {
+			return rr, true
+		}
+	}
+	return runeRange{}, false
+}
@@ -0,0 +1,39 @@
+package emoji
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetector_WithTrace(t *testing.T) {
+	var trace strings.Builder
+	detector := NewDetectorWithAllowed([]string{"✅"}).WithTrace(&trace)
+	detector.FindEmojis("Hi 😊 é ✅ あ")
+
+	for _, want := range []string{
+		`trace: U+1F60A "😊" emoji (range U+1F300-U+1F64F)`,
+		`trace: U+2705 "✅" allowed emoji (range U+2600-U+27BF)`,
+		`trace: U+00E9 "é" not an emoji (below U+2600, the lowest range)`,
+		`trace: U+3042 "あ" not an emoji (no range matches)`,
+	} {
+		if !strings.Contains(trace.String(), want) {
+			t.Errorf("Trace missing %q, got:\n%s", want, trace.String())
+		}
+	}
+	if strings.Contains(trace.String(), "U+0048") {
+		t.Errorf("ASCII should not be traced, got:\n%s", trace.String())
+	}
+}
+
+func TestDetector_WithTraceTagSequence(t *testing.T) {
+	var trace strings.Builder
+	detector := NewDetector().WithTrace(&trace)
+	detector.FindEmojis("🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F")
+
+	if !strings.Contains(trace.String(), "U+1F3F4") || !strings.Contains(trace.String(), "24-byte tag sequence") {
+		t.Errorf("Expected the flag and its tag sequence traced, got:\n%s", trace.String())
+	}
+	if lines := strings.Count(trace.String(), "\n"); lines != 1 {
+		t.Errorf("Expected one trace line for the tag sequence, got %d:\n%s", lines, trace.String())
+	}
+}
@@ -0,0 +1,114 @@
+package emoji
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxListedDifferences caps how many differences a DifferenceError message lists.
+const maxListedDifferences = 20
+
+// Difference is a change between an original and a cleaned text that removing emojis
+// does not explain.
+type Difference struct {
+	Line     int    // 1-based line in the original
+	Column   int    // 1-based column in the original, in characters
+	Original string // The original character, or empty past the end of the original
+	Cleaned  string // The cleaned character, or empty past the end of the cleaned text
+}
+
+// String describes the difference for a report.
+func (diff Difference) String() string {
+	switch {
+	case diff.Cleaned == "":
+		return fmt.Sprintf("line %d, column %d: %q was removed", diff.Line, diff.Column, diff.Original)
+	case diff.Original == "":
+		return fmt.Sprintf("line %d, column %d: %q was added", diff.Line, diff.Column, diff.Cleaned)
+	}
+	return fmt.Sprintf("line %d, column %d: %q became %q", diff.Line, diff.Column, diff.Original, diff.Cleaned)
+}
+
+// DifferenceError is returned by VerifyCleaned when the cleaned text differs from the
+// original by more than removed emojis and whitespace.
+type DifferenceError struct {
+	Differences []Difference
+}
+
+func (e *DifferenceError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d difference(s) besides removed emojis and whitespace:", len(e.Differences))
+	for i, diff := range e.Differences {
+		if i == maxListedDifferences {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(e.Differences)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s", diff)
+	}
+	return b.String()
+}
+
+// VerifyCleaned checks that cleaned can be obtained from original by removing emojis,
+// their joiners, variation selectors and tags, and whitespace, and nothing else. It
+// returns a *DifferenceError listing every other change, or nil. Allowed emojis may be
+// removed too, and replacements are reported as differences. Literal emojis only are
+// recognized, not encoded ones.
+func (d *Detector) VerifyCleaned(original, cleaned string) error {
+	var diffs []Difference
+	line, column := 1, 1
+	i, j := 0, 0
+	for i < len(original) {
+		r, size := utf8.DecodeRuneInString(original[i:])
+		_, cSize := utf8.DecodeRuneInString(cleaned[j:])
+		char, cleanedChar := original[i:i+size], cleaned[j:j+cSize]
+
+		switch {
+		case cSize > 0 && char == cleanedChar:
+			j += cSize
+		case d.isEmojiRune(r) || isEmojiComponent(r) || unicode.IsSpace(r):
+			// Removed, as cleaning may do
+		case cSize > 0 && sameNextRune(original[i+size:], cleaned[j+cSize:]):
+			diffs = append(diffs, Difference{Line: line, Column: column, Original: char, Cleaned: cleanedChar})
+			j += cSize
+		case cSize == 0 || strings.HasPrefix(original[i+size:], cleanedChar):
+			diffs = append(diffs, Difference{Line: line, Column: column, Original: char})
+		case strings.HasPrefix(cleaned[j+cSize:], char):
+			diffs = append(diffs, Difference{Line: line, Column: column, Cleaned: cleanedChar})
+			j += cSize
+			continue // Compare the same original character again
+		default:
+			diffs = append(diffs, Difference{Line: line, Column: column, Original: char, Cleaned: cleanedChar})
+			j += cSize
+		}
+
+		i += size
+		column++
+		if r == '\n' {
+			line, column = line+1, 1
+		}
+	}
+	for j < len(cleaned) {
+		_, cSize := utf8.DecodeRuneInString(cleaned[j:])
+		diffs = append(diffs, Difference{Line: line, Column: column, Cleaned: cleaned[j : j+cSize]})
+		j += cSize
+	}
+
+	if len(diffs) > 0 {
+		return &DifferenceError{Differences: diffs}
+	}
+	return nil
+}
+
+// sameNextRune reports whether a and b start with the same character, so the
+// characters before them are best explained as one replaced by the other.
+func sameNextRune(a, b string) bool {
+	_, size := utf8.DecodeRuneInString(a)
+	return size > 0 && strings.HasPrefix(b, a[:size])
+}
+
+// isEmojiComponent reports whether r only forms part of an emoji: a zero width joiner,
+// variation selector, skin tone modifier or tag character.
+func isEmojiComponent(r rune) bool {
+	return r == zeroWidthJoiner || r == variationSelector16 || isSkinToneModifier(r) || isTagRune(r)
+}
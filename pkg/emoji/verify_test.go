@@ -0,0 +1,49 @@
+package emoji
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetector_VerifyCleaned(t *testing.T) {
+	d := NewDetector()
+	original := "# Release 🚀\n\nI ❤️ Go 👍🏽\n✅\n\nend"
+
+	passing := map[string]string{
+		"removed emojis":                    d.RemoveEmojis(original),
+		"collapsed spaces and deleted line": "# Release\n\nI Go\n\nend",
+		"unchanged":                         original,
+	}
+	for name, cleaned := range passing {
+		if err := d.VerifyCleaned(original, cleaned); err != nil {
+			t.Errorf("%s: VerifyCleaned() error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cleaned string
+		want    []Difference
+	}{
+		{"changed letter", "# Relaase \n\nI Go \n\nend", []Difference{{Line: 1, Column: 6, Original: "e", Cleaned: "a"}}},
+		{"removed letter", "# Release \n\nI G \n\nend", []Difference{{Line: 3, Column: 7, Original: "o"}}},
+		{"added letter", "# Release \n\nI Go \n\nends", []Difference{{Line: 6, Column: 4, Cleaned: "s"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := d.VerifyCleaned(original, tt.cleaned)
+			var diffErr *DifferenceError
+			if !errors.As(err, &diffErr) {
+				t.Fatalf("VerifyCleaned() error = %v, want a *DifferenceError", err)
+			}
+			if !reflect.DeepEqual(diffErr.Differences, tt.want) {
+				t.Errorf("Differences = %+v, want %+v", diffErr.Differences, tt.want)
+			}
+			if !strings.Contains(err.Error(), "1 difference(s)") {
+				t.Errorf("Error() = %q", err.Error())
+			}
+		})
+	}
+}
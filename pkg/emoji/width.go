@@ -0,0 +1,111 @@
+package emoji
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ColumnMode controls how columns are counted in reported locations.
+type ColumnMode string
+
+const (
+	// ColumnRune counts one column per character.
+	ColumnRune ColumnMode = "rune"
+	// ColumnByte counts one column per UTF-8 byte.
+	ColumnByte ColumnMode = "byte"
+	// ColumnDisplay counts terminal display width, where East Asian wide and
+	// fullwidth characters and emojis take two columns and combining marks none.
+	ColumnDisplay ColumnMode = "display"
+)
+
+// ParseColumnMode converts a string to a ColumnMode.
+func ParseColumnMode(s string) (ColumnMode, error) {
+	switch mode := ColumnMode(s); mode {
+	case ColumnRune, ColumnByte, ColumnDisplay:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid column mode: %s (must be 'rune', 'byte' or 'display')", s)
+}
+
+// wideRanges are the East Asian Wide and Fullwidth ranges, plus the emoji blocks
+// that default to emoji presentation.
+var wideRanges = []runeRange{
+	{0x1100, 0x115F}, // Hangul Jamo initial consonants
+	{0x2614, 0x2615}, // Emoji presentation symbols in Miscellaneous Symbols and Dingbats
+	{0x2648, 0x2653},
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2E80, 0x303E},   // CJK Radicals through CJK Symbols and Punctuation
+	{0x3041, 0x33FF},   // Hiragana through CJK Compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi Syllables and Radicals
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+	{0xFF00, 0xFF60},   // Fullwidth Forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F0CF, 0x1F0CF}, // Playing card black joker
+	{0x1F18E, 0x1F18E}, // Squared AB
+	{0x1F191, 0x1F19A}, // Squared CL through VS
+	{0x1F300, 0x1F64F}, // Misc Symbols and Pictographs, Emoticons
+	{0x1F680, 0x1F6FF}, // Transport and Map Symbols
+	{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+	{0x1FA70, 0x1FAFF}, // Symbols and Pictographs Extended-A
+	{0x20000, 0x2FFFD}, // CJK Unified Ideographs Extension B and later
+	{0x30000, 0x3FFFD}, // CJK Unified Ideographs Extension G and later
+}
+
+// runeWidth returns the number of display columns a character occupies.
+func runeWidth(r rune) int {
+	if r == zeroWidthJoiner || r == variationSelector16 || isTagRune(r) || unicode.Is(unicode.Mn, r) {
+		return 0
+	}
+	for _, rr := range wideRanges {
+		if r < rr.lo {
+			break // Ranges are sorted, so no later range can match
+		}
+		if r <= rr.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// columnWidth returns the number of columns s spans in the given mode.
+func columnWidth(s string, mode ColumnMode) int {
+	switch mode {
+	case ColumnByte:
+		return len(s)
+	case ColumnDisplay:
+		width := 0
+		for _, r := range s {
+			width += runeWidth(r)
+		}
+		return width
+	default:
+		return utf8.RuneCountInString(s)
+	}
+}
@@ -154,7 +154,7 @@ echo "✓ Stdin input with --no-dry-run modifies files"
 echo "Test 4: Testing error handling for non-existent files..."
 
 echo "/non/existent/file.txt" | "$BINARY" --files-from-stdin - 2>error_output_$$ >/dev/null || true
-if ! grep -q "Warning.*does not exist" error_output_$$; then
+if ! grep -q "level=WARN.*does not exist" error_output_$$; then
     echo "FAIL: Should warn about non-existent files"
     cat error_output_$$
     exit 1
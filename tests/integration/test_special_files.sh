@@ -122,9 +122,9 @@ echo "✓ Correctly skips binary files"
 echo "✓ Correctly skips socket files"
 echo "✓ Processes only regular text files"
 
-# Test 2: Actual processing with --no-dry-run
+# Test 2: Actual processing with --no-dry-run (--yes because the test dir is a repository root)
 echo "Test 2: Running with --no-dry-run..."
-output=$("$BINARY" --no-dry-run "$TEST_DIR" 2>&1)
+output=$("$BINARY" --no-dry-run --yes "$TEST_DIR" 2>&1)
 exit_code=$?
 
 if [ $exit_code -ne 0 ]; then